@@ -3,8 +3,10 @@ package curator
 import (
 	"errors"
 	"path"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
 )
@@ -14,6 +16,7 @@ type ChildrenCacheEvent struct {
 	Data      []byte
 	Stat      *zk.Stat
 	Type      ChildrenCacheEventType
+	Err       error
 }
 
 type ChildrenCacheEventType int
@@ -22,6 +25,9 @@ const (
 	ChildrenCacheAdd    ChildrenCacheEventType = 1
 	ChildrenCacheUpdate ChildrenCacheEventType = 2
 	ChildrenCacheDel    ChildrenCacheEventType = 3
+	ChildrenCacheError  ChildrenCacheEventType = 4
+
+	ChildrenCacheInitialized ChildrenCacheEventType = 5
 )
 
 func (c ChildrenCacheEventType) String() string {
@@ -32,18 +38,39 @@ func (c ChildrenCacheEventType) String() string {
 		return "ChildrenCacheDel"
 	case ChildrenCacheUpdate:
 		return "ChildrenCacheUpdate"
+	case ChildrenCacheError:
+		return "ChildrenCacheError"
+	case ChildrenCacheInitialized:
+		return "ChildrenCacheInitialized"
 	}
 	return "unknown"
 }
 
+type ChildrenCacheStartMode int
+
+const (
+	// ChildrenCacheStartNormal loads the children in background and
+	// emits ChildrenCacheAdd for each of them.
+	ChildrenCacheStartNormal ChildrenCacheStartMode = 0
+	// ChildrenCacheBuildInitialCache loads the children before Start
+	// returns, without emitting events for them.
+	ChildrenCacheBuildInitialCache ChildrenCacheStartMode = 1
+	// ChildrenCachePostInitializedEvent behaves like ChildrenCacheStartNormal
+	// and emits ChildrenCacheInitialized once the initial children are loaded.
+	ChildrenCachePostInitializedEvent ChildrenCacheStartMode = 2
+)
+
 type OnChildrenCacheChange func(event ChildrenCacheEvent)
 
 type childContext struct {
-	done chan struct{}
-	wg   sync.WaitGroup
-	lock sync.RWMutex
-	stat *zk.Stat
-	data []byte
+	done    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+	lock    sync.RWMutex
+	added   bool
+	initial bool
+	stat    *zk.Stat
+	data    []byte
 }
 
 func newChildContext() *childContext {
@@ -52,6 +79,11 @@ func newChildContext() *childContext {
 	}
 }
 
+func (c *childContext) stop() {
+	c.once.Do(func() { close(c.done) })
+	c.wg.Wait()
+}
+
 func (c *childContext) getDataAndStat() ([]byte, *zk.Stat) {
 	c.lock.RLock()
 	data, stat := c.data, c.stat
@@ -59,47 +91,105 @@ func (c *childContext) getDataAndStat() ([]byte, *zk.Stat) {
 	return data, stat
 }
 
-func (c *childContext) update(data []byte, stat *zk.Stat) {
+func (c *childContext) isAdded() bool {
+	c.lock.RLock()
+	added := c.added
+	c.lock.RUnlock()
+	return added
+}
+
+func (c *childContext) load(data []byte, stat *zk.Stat) {
 	c.lock.Lock()
+	c.added = true
 	c.data, c.stat = data, stat
 	c.lock.Unlock()
 }
 
+func (c *childContext) takeInitial() bool {
+	c.lock.Lock()
+	initial := c.initial
+	c.initial = false
+	c.lock.Unlock()
+	return initial
+}
+
+func (c *childContext) update(data []byte, stat *zk.Stat) ChildrenCacheEventType {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var eventType ChildrenCacheEventType
+	if !c.added {
+		eventType = ChildrenCacheAdd
+	} else if c.stat == nil || c.stat.Mzxid != stat.Mzxid || c.stat.Czxid != stat.Czxid {
+		eventType = ChildrenCacheUpdate
+	}
+	c.added = true
+	c.data, c.stat = data, stat
+	return eventType
+}
+
 type ChildrenCache struct {
-	start    int32
-	client   *ZookeeperClient
-	node     string
-	callback OnChildrenCacheChange
-	mutex    *sync.RWMutex
-	cache    map[string]*childContext
-	quit     chan struct{}
-	wg       sync.WaitGroup
+	start          int32
+	client         *ZookeeperClient
+	node           string
+	callback       OnChildrenCacheChange
+	mutex          *sync.RWMutex
+	cache          map[string]*childContext
+	cacheData      bool
+	postInitialize bool
+	initialPending int32
+	quit           chan struct{}
+	wg             sync.WaitGroup
 }
 
 func NewChildrenCache(client *ZookeeperClient, node string, callback OnChildrenCacheChange) *ChildrenCache {
 	return &ChildrenCache{
-		client:   client,
-		node:     node,
-		callback: callback,
-		mutex:    new(sync.RWMutex),
-		cache:    make(map[string]*childContext),
+		client:    client,
+		node:      node,
+		callback:  callback,
+		mutex:     new(sync.RWMutex),
+		cache:     make(map[string]*childContext),
+		cacheData: true,
 	}
 }
 
+// WithCacheData controls whether the data of every child is fetched and
+// watched. Without it only the child names are tracked, events carry no
+// data and no watch is set on the children.
+func (w *ChildrenCache) WithCacheData(cacheData bool) *ChildrenCache {
+	w.cacheData = cacheData
+	return w
+}
+
 func (w *ChildrenCache) Start() error {
+	return w.StartWithMode(ChildrenCacheStartNormal)
+}
+
+func (w *ChildrenCache) StartWithMode(mode ChildrenCacheStartMode) error {
 	if !atomic.CompareAndSwapInt32(&w.start, 0, 1) {
 		return errors.New("curator: ChildrenCache already started")
 	}
 
 	exist, _, err := w.client.Exists(w.node)
 	if err != nil {
+		atomic.StoreInt32(&w.start, 0)
 		return err
 	}
 	if !exist {
+		atomic.StoreInt32(&w.start, 0)
 		return errors.New("curator: node is not exist, node: " + w.node)
 	}
 
 	w.quit = make(chan struct{}, 1)
+	w.postInitialize = mode == ChildrenCachePostInitializedEvent
+	if mode == ChildrenCacheBuildInitialCache {
+		if err := w.Rebuild(); err != nil {
+			w.Clear()
+			atomic.StoreInt32(&w.start, 0)
+			return err
+		}
+	}
+
 	w.wg.Add(1)
 	go w.watchChildren()
 	return nil
@@ -119,73 +209,236 @@ func (w *ChildrenCache) Get(child string) (data []byte, stat *zk.Stat, ok bool)
 	w.mutex.RLock()
 	ctx := w.cache[child]
 	w.mutex.RUnlock()
-	if ctx != nil {
+	if ctx != nil && ctx.isAdded() {
 		ok = true
 		data, stat = ctx.getDataAndStat()
 	}
 	return
 }
 
+func (w *ChildrenCache) GetCurrentData() []ChildData {
+	w.mutex.RLock()
+	children := make([]string, 0, len(w.cache))
+	contexts := make([]*childContext, 0, len(w.cache))
+	for child, ctx := range w.cache {
+		children = append(children, child)
+		contexts = append(contexts, ctx)
+	}
+	w.mutex.RUnlock()
+
+	result := make([]ChildData, 0, len(children))
+	for i, child := range children {
+		if contexts[i].isAdded() {
+			data, stat := contexts[i].getDataAndStat()
+			result = append(result, ChildData{Path: path.Join(w.node, child), Data: data, Stat: stat})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result
+}
+
+// Clear drops all cached children without generating events.
+func (w *ChildrenCache) Clear() {
+	w.mutex.Lock()
+	cache := w.cache
+	w.cache = make(map[string]*childContext)
+	w.mutex.Unlock()
+
+	for _, ctx := range cache {
+		ctx.stop()
+	}
+}
+
+// Rebuild reloads all children and their data synchronously without
+// generating events.
+func (w *ChildrenCache) Rebuild() error {
+	if atomic.LoadInt32(&w.start) != 1 {
+		return errors.New("curator: ChildrenCache is not started")
+	}
+
+	children, _, err := w.client.Children(w.node)
+	if err != nil && err != zk.ErrNoNode {
+		return err
+	}
+
+	loaded := make(map[string]*childContext, len(children))
+	for _, child := range children {
+		var data []byte
+		var stat *zk.Stat
+		if w.cacheData {
+			data, stat, err = w.client.Get(path.Join(w.node, child))
+			if err != nil {
+				if err == zk.ErrNoNode {
+					continue
+				}
+				return err
+			}
+		}
+		ctx := newChildContext()
+		ctx.load(data, stat)
+		loaded[child] = ctx
+	}
+
+	var removed []*childContext
+	w.mutex.Lock()
+	for child, ctx := range w.cache {
+		if _, ok := loaded[child]; !ok {
+			delete(w.cache, child)
+			removed = append(removed, ctx)
+		}
+	}
+	for child, ctx := range loaded {
+		if v, ok := w.cache[child]; ok {
+			v.load(ctx.getDataAndStat())
+			continue
+		}
+		w.cache[child] = ctx
+		if w.cacheData {
+			ctx.wg.Add(1)
+			go w.watchChild(child, ctx)
+		}
+	}
+	w.mutex.Unlock()
+
+	for _, ctx := range removed {
+		ctx.stop()
+	}
+	return nil
+}
+
 func (w *ChildrenCache) watchChildren() {
 	Log.Infoln("curator: start ChildrenCache.watchChildren", w.node)
 	defer func() {
 		Log.Infoln("curator.zk: stop ChildrenCache.watchChildren", w.node)
 		w.mutex.Lock()
-		for _, ctx := range w.cache {
-			close(ctx.done)
-			ctx.wg.Wait()
-		}
+		cache := w.cache
+		w.cache = make(map[string]*childContext)
 		w.mutex.Unlock()
 
+		for _, ctx := range cache {
+			ctx.stop()
+		}
+
 		w.wg.Done()
 	}()
 
 	for {
 		children, _, eventChan, err := w.client.ChildrenW(w.node)
-		if err != nil {
-			Log.Errorln("curator: failed to client.ChildrenW, node:", w.node, "err:", err)
-			return
-		}
-
-		w.mutex.Lock()
-		oldCache := make(map[string]*childContext)
-		newCache := make(map[string]*childContext)
-		for _, child := range children {
-			if v, ok := w.cache[child]; ok {
-				oldCache[child] = v
-				delete(w.cache, child)
+		if err == zk.ErrNoNode {
+			Log.Warnln("curator: ChildrenCache find node had been deleted, node:", w.node)
+			w.updateChildren(nil)
+
+			// wait for the node to be created again
+			var exist bool
+			exist, _, eventChan, err = w.client.ExistsW(w.node)
+			if err == nil && exist {
 				continue
 			}
-			newCache[child] = newChildContext()
-		}
-
-		for _, ctx := range w.cache {
-			close(ctx.done)
-			ctx.wg.Wait()
+		} else if err == nil {
+			w.updateChildren(children)
 		}
-		w.cache = oldCache
 
-		for child, ctx := range newCache {
-			w.cache[child] = ctx
-			ctx.wg.Add(1)
-			go w.watchChild(child, ctx)
+		if err != nil {
+			w.notifyError(w.node, err)
+			select {
+			case <-w.quit:
+				return
+			case <-time.After(cacheRetryInterval):
+			}
+			continue
 		}
-		w.mutex.Unlock()
 
 		select {
 		case <-w.quit:
 			return
 		case event, ok := <-eventChan:
-			if !ok {
-				return
+			// EventNotWatching after session expiry also lands here, the next
+			// round rebuilds the cache and diffs it against the old snapshot.
+			if ok && event.Type == zk.EventNotWatching {
+				Log.Warnln("curator: ChildrenCache lost watch, node:", w.node, "err:", event.Err)
 			}
+		}
+	}
+}
 
-			if event.Type == zk.EventNodeDeleted {
-				Log.Warnln("curator: ChildrenCache find node had been deleted, node:", w.node)
-				return
+func (w *ChildrenCache) updateChildren(children []string) {
+	current := make(map[string]bool, len(children))
+	for _, child := range children {
+		current[child] = true
+	}
+
+	postInitialize := w.postInitialize
+	w.postInitialize = false
+	if postInitialize {
+		atomic.StoreInt32(&w.initialPending, 1)
+	}
+
+	var removed, added []string
+	w.mutex.Lock()
+	for child := range w.cache {
+		if !current[child] {
+			removed = append(removed, child)
+		}
+	}
+	for _, child := range children {
+		if _, ok := w.cache[child]; !ok {
+			ctx := newChildContext()
+			if !w.cacheData {
+				ctx.load(nil, nil)
+				w.cache[child] = ctx
+				added = append(added, child)
+				continue
 			}
+			if postInitialize {
+				ctx.initial = true
+				atomic.AddInt32(&w.initialPending, 1)
+			}
+			w.cache[child] = ctx
+			ctx.wg.Add(1)
+			go w.watchChild(child, ctx)
 		}
 	}
+	w.mutex.Unlock()
+
+	for _, child := range removed {
+		w.removeChild(child, nil)
+	}
+	for _, child := range added {
+		w.notify(ChildrenCacheEvent{ChildNode: path.Join(w.node, child), Type: ChildrenCacheAdd})
+	}
+
+	if postInitialize {
+		w.initialLoaded()
+	}
+}
+
+func (w *ChildrenCache) initialLoaded() {
+	if atomic.AddInt32(&w.initialPending, -1) == 0 {
+		w.notify(ChildrenCacheEvent{ChildNode: w.node, Type: ChildrenCacheInitialized})
+	}
+}
+
+func (w *ChildrenCache) removeChild(child string, ctx *childContext) {
+	w.mutex.Lock()
+	v, ok := w.cache[child]
+	if ok && (ctx == nil || ctx == v) {
+		delete(w.cache, child)
+	}
+	w.mutex.Unlock()
+
+	if !ok || (ctx != nil && ctx != v) {
+		return
+	}
+
+	if ctx == nil {
+		// called from watchChildren, wait for watchChild to stop
+		v.stop()
+	}
+
+	if v.isAdded() {
+		data, stat := v.getDataAndStat()
+		w.notify(ChildrenCacheEvent{ChildNode: path.Join(w.node, child), Data: data, Stat: stat, Type: ChildrenCacheDel})
+	}
 }
 
 func (w *ChildrenCache) watchChild(child string, ctx *childContext) {
@@ -197,22 +450,33 @@ func (w *ChildrenCache) watchChild(child string, ctx *childContext) {
 		ctx.wg.Done()
 	}()
 
-	eventType := ChildrenCacheAdd
 	for {
 		data, stat, eventChan, err := w.client.GetW(childPath)
-		if err != nil {
-			Log.Warnln("curator: failed to GetW, childPath:", childPath, "err:", err)
+		if err == zk.ErrNoNode {
+			w.removeChild(child, ctx)
+			if ctx.takeInitial() {
+				w.initialLoaded()
+			}
 			return
 		}
 
-		if eventType == ChildrenCacheAdd || eventType == ChildrenCacheUpdate {
-			ctx.update(data, stat)
+		if err != nil {
+			w.notifyError(childPath, err)
+			select {
+			case <-w.quit:
+				return
+			case <-ctx.done:
+				return
+			case <-time.After(cacheRetryInterval):
+			}
+			continue
 		}
 
-		w.notify(ChildrenCacheEvent{ChildNode: childPath, Data: data, Stat: stat, Type: eventType})
-
-		if eventType == ChildrenCacheAdd {
-			eventType = ChildrenCacheUpdate
+		if eventType := ctx.update(data, stat); eventType != 0 {
+			w.notify(ChildrenCacheEvent{ChildNode: childPath, Data: data, Stat: stat, Type: eventType})
+		}
+		if ctx.takeInitial() {
+			w.initialLoaded()
 		}
 
 		select {
@@ -220,19 +484,16 @@ func (w *ChildrenCache) watchChild(child string, ctx *childContext) {
 			return
 		case <-ctx.done:
 			return
-		case event, ok := <-eventChan:
-			if !ok {
-				return
-			}
-
-			if event.Type == zk.EventNodeDeleted {
-				w.notify(ChildrenCacheEvent{ChildNode: childPath, Type: ChildrenCacheDel})
-				return
-			}
+		case <-eventChan:
 		}
 	}
 }
 
+func (w *ChildrenCache) notifyError(node string, err error) {
+	Log.Errorln("curator: ChildrenCache failed to watch node:", node, "err:", err)
+	w.notify(ChildrenCacheEvent{ChildNode: node, Type: ChildrenCacheError, Err: err})
+}
+
 func (w *ChildrenCache) notify(event ChildrenCacheEvent) {
 	Log.Infoln("curator: ChildrenCache node:", event.ChildNode, "event:", event.Type)
 
@@ -179,6 +179,54 @@ func TestChildCache_StartWithInvalidNode(t *testing.T) {
 	}
 }
 
+func TestChildrenCache_ParentDeleted(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal("failed to newZookeeperClient, err:", err)
+	}
+	defer client.Close()
+
+	childNode := path.Join(childrenCacheNode, "child")
+	CreateAll(client, childNode, nil, 0, zk.WorldACL(zk.PermAll))
+	defer DeleteAll(client, childrenCacheNode)
+
+	c := make(chan ChildrenCacheEvent, 10)
+	cache := NewChildrenCache(client, childrenCacheNode, func(event ChildrenCacheEvent) {
+		c <- event
+	})
+	if err := cache.Start(); err != nil {
+		t.Fatal("failed to cache.Start, err:", err)
+	}
+	defer cache.Close()
+
+	expectEvent := func(expectedType ChildrenCacheEventType) {
+		select {
+		case event := <-c:
+			if event.Type != expectedType || event.ChildNode != childNode {
+				t.Fatal("unexpected event:", event.Type, event.ChildNode)
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatal("deadline waiting for", expectedType)
+		}
+	}
+
+	expectEvent(ChildrenCacheAdd)
+
+	if err := DeleteAll(client, childrenCacheNode); err != nil {
+		t.Fatal("failed to DeleteAll, err:", err)
+	}
+	expectEvent(ChildrenCacheDel)
+	if _, _, ok := cache.Get("child"); ok {
+		t.Fatal("unexpected Get result for deleted child")
+	}
+
+	CreateAll(client, childNode, nil, 0, zk.WorldACL(zk.PermAll))
+	expectEvent(ChildrenCacheAdd)
+	if _, _, ok := cache.Get("child"); !ok {
+		t.Fatal("unexpected Get result for recreated child")
+	}
+}
+
 func TestChildrenCache_Update(t *testing.T) {
 	client, err := newZooKeeperClient()
 	if err != nil {
@@ -187,3 +235,135 @@ func TestChildrenCache_Update(t *testing.T) {
 	defer client.Close()
 
 }
+
+func TestChildrenCache_StartWithMode(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal("failed to newZookeeperClient, err:", err)
+	}
+	defer client.Close()
+
+	DeleteAll(client, childrenCacheNode)
+	for i := 0; i < 3; i++ {
+		node := path.Join(childrenCacheNode, strconv.Itoa(2-i))
+		if _, err := CreateAll(client, node, []byte(strconv.Itoa(2-i)), 0, zk.WorldACL(zk.PermAll)); err != nil {
+			t.Fatal("failed to CreateAll, err:", err)
+		}
+	}
+	defer DeleteAll(client, childrenCacheNode)
+
+	c := make(chan ChildrenCacheEvent, 10)
+	cache := NewChildrenCache(client, childrenCacheNode, func(event ChildrenCacheEvent) {
+		c <- event
+	})
+	if err := cache.StartWithMode(ChildrenCacheBuildInitialCache); err != nil {
+		t.Fatal("failed to cache.StartWithMode, err:", err)
+	}
+
+	current := cache.GetCurrentData()
+	if len(current) != 3 {
+		t.Fatal("unexpected current data:", current)
+	}
+	for i, v := range current {
+		if v.Path != path.Join(childrenCacheNode, strconv.Itoa(i)) || !bytes.Equal(v.Data, []byte(strconv.Itoa(i))) {
+			t.Fatal("unexpected child data:", v.Path, string(v.Data))
+		}
+	}
+
+	select {
+	case event := <-c:
+		t.Fatal("unexpected event:", event.Type, event.ChildNode)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	cache.Clear()
+	if current := cache.GetCurrentData(); len(current) != 0 {
+		t.Fatal("unexpected current data after Clear:", current)
+	}
+	if err := cache.Rebuild(); err != nil {
+		t.Fatal("failed to cache.Rebuild, err:", err)
+	}
+	if current := cache.GetCurrentData(); len(current) != 3 {
+		t.Fatal("unexpected current data after Rebuild:", current)
+	}
+	cache.Close()
+
+	c = make(chan ChildrenCacheEvent, 10)
+	cache = NewChildrenCache(client, childrenCacheNode, func(event ChildrenCacheEvent) {
+		c <- event
+	})
+	if err := cache.StartWithMode(ChildrenCachePostInitializedEvent); err != nil {
+		t.Fatal("failed to cache.StartWithMode, err:", err)
+	}
+	defer cache.Close()
+
+	added := 0
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-c:
+			switch event.Type {
+			case ChildrenCacheAdd:
+				added++
+				continue
+			case ChildrenCacheInitialized:
+			default:
+				t.Fatal("unexpected event:", event.Type, event.ChildNode)
+			}
+		case <-deadline:
+			t.Fatal("deadline waiting for ChildrenCacheInitialized")
+		}
+		break
+	}
+	if added != 3 {
+		t.Fatal("unexpected added count before ChildrenCacheInitialized:", added)
+	}
+}
+
+func TestChildrenCache_WithoutCacheData(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal("failed to newZookeeperClient, err:", err)
+	}
+	defer client.Close()
+
+	DeleteAll(client, childrenCacheNode)
+	if _, err := CreateAll(client, childrenCacheNode, nil, 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to CreateAll, err:", err)
+	}
+	defer DeleteAll(client, childrenCacheNode)
+
+	c := make(chan ChildrenCacheEvent, 10)
+	cache := NewChildrenCache(client, childrenCacheNode, func(event ChildrenCacheEvent) {
+		c <- event
+	}).WithCacheData(false)
+	if err := cache.Start(); err != nil {
+		t.Fatal("failed to cache.Start, err:", err)
+	}
+	defer cache.Close()
+
+	expectEvent := func(expectedType ChildrenCacheEventType, expectedNode string) {
+		select {
+		case event := <-c:
+			if event.Type != expectedType || event.ChildNode != expectedNode || event.Data != nil {
+				t.Fatal("unexpected event:", event.Type, event.ChildNode, string(event.Data))
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatal("deadline waiting for", expectedType, expectedNode)
+		}
+	}
+
+	node := path.Join(childrenCacheNode, "a")
+	if _, err := client.Create(node, []byte("a"), 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to client.Create, err:", err)
+	}
+	expectEvent(ChildrenCacheAdd, node)
+
+	if _, err := client.Set(node, []byte("b"), -1); err != nil {
+		t.Fatal("failed to client.Set, err:", err)
+	}
+	if err := client.Delete(node, -1); err != nil {
+		t.Fatal("failed to client.Delete, err:", err)
+	}
+	expectEvent(ChildrenCacheDel, node)
+}
@@ -25,4 +25,6 @@ type Conn interface {
 
 	GetACL(path string) ([]zk.ACL, *zk.Stat, error)
 	SetACL(path string, acl []zk.ACL, version int32) (*zk.Stat, error)
+
+	Multi(ops ...interface{}) ([]zk.MultiResponse, error)
 }
@@ -17,6 +17,7 @@ type connHolder struct {
 	connectionString  string
 	mutex             sync.Mutex
 	processEvent      func(zk.Event)
+	processOutcome    func(connString string, succeeded bool)
 }
 
 func (c *connHolder) getConn() Conn {
@@ -52,15 +53,19 @@ func (c *connHolder) closeAndReset() error {
 	c.connectionString = c.ensemble.GetConnectionString()
 	conn, watch, err := c.factory.Create(c.connectionString, c.sessionTimeout, c.connectionTimeout, c.canBeReadOnly)
 	if err != nil {
+		c.processOutcome(c.connectionString, false)
 		return err
 	}
 	c.conn = conn
-	go c.watch(watch)
+	go c.watch(watch, c.connectionString)
 
 	return nil
 }
 
-func (c *connHolder) watch(eventChan <-chan zk.Event) {
+func (c *connHolder) watch(eventChan <-chan zk.Event, connString string) {
+	// an attempt fails when it connects again or disconnects before the
+	// session is established
+	attempting := false
 	for {
 		select {
 		case event, ok := <-eventChan:
@@ -68,6 +73,21 @@ func (c *connHolder) watch(eventChan <-chan zk.Event) {
 			if !ok {
 				return
 			}
+			switch event.State {
+			case zk.StateConnecting:
+				if attempting {
+					c.processOutcome(connString, false)
+				}
+				attempting = true
+			case zk.StateHasSession, zk.StateConnectedReadOnly:
+				attempting = false
+				c.processOutcome(connString, true)
+			case zk.StateDisconnected:
+				if attempting {
+					c.processOutcome(connString, false)
+				}
+				attempting = false
+			}
 			c.processEvent(event)
 		}
 	}
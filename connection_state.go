@@ -9,7 +9,10 @@ import (
 	"github.com/samuel/go-zookeeper/zk"
 )
 
-var ErrConnectionLoss = errors.New("curator: connection loss")
+var (
+	ErrConnectionLoss = errors.New("curator: connection loss")
+	ErrReadOnly       = errors.New("curator: session is read-only")
+)
 
 const (
 	ConnDisconnected = 0
@@ -24,8 +27,9 @@ type connectionState struct {
 	connectionTimeout   time.Duration
 	connectionStartTime time.Duration
 	connected           int32
+	readOnly            int32
 	errQueue            *errorQueue
-	connStateWatches    []chan<- zk.State
+	stateManager        *connectionStateManager
 	checkMutex          sync.Mutex
 }
 
@@ -40,6 +44,7 @@ func newConnectionState(
 		sessionTimeout:    sessionTimeout,
 		connectionTimeout: connectionTimeout,
 		errQueue:          newErrorQueue(10),
+		stateManager:      newConnectionStateManager(),
 		watcherManager:    newWatcherManager(),
 	}
 
@@ -50,6 +55,7 @@ func newConnectionState(
 		sessionTimeout:    sessionTimeout,
 		connectionTimeout: connectionTimeout,
 		processEvent:      state.processEvent,
+		processOutcome:    state.processOutcome,
 	}
 	state.holder = holder
 
@@ -67,6 +73,7 @@ func (c *connectionState) Close() error {
 	c.ensemble.Close()
 	c.holder.closeAndClear()
 	atomic.StoreInt32(&c.connected, ConnDisconnected)
+	atomic.StoreInt32(&c.readOnly, 0)
 	return nil
 }
 
@@ -74,6 +81,12 @@ func (c *connectionState) IsConnected() bool {
 	return atomic.LoadInt32(&c.connected) == ConnConnected
 }
 
+// IsReadOnly reports whether the session is served by a read-only server,
+// which happens only when the client was built with canBeReadOnly.
+func (c *connectionState) IsReadOnly() bool {
+	return atomic.LoadInt32(&c.readOnly) == 1
+}
+
 func (c *connectionState) getConn() (Conn, error) {
 	if err := c.errQueue.Pop(); err != nil {
 		return nil, err
@@ -117,15 +130,33 @@ func (c *connectionState) checkTimeout() error {
 
 func (c *connectionState) reset() error {
 	atomic.StoreInt32(&c.connected, ConnDisconnected)
+	atomic.StoreInt32(&c.readOnly, 0)
 	atomic.StoreInt64((*int64)(&c.connectionStartTime), time.Now().UnixNano())
 	return c.holder.closeAndReset()
 }
 
+func (c *connectionState) processOutcome(connString string, succeeded bool) {
+	if observer, ok := c.ensemble.(ConnectionObserver); ok {
+		if succeeded {
+			observer.ConnectionSucceeded(connString)
+		} else {
+			observer.ConnectionFailed(connString)
+		}
+	}
+}
+
 func (c *connectionState) processEvent(event zk.Event) {
 	Log.Infof("curator: got conn event: %+v", event)
 
 	checkConnectionString := true
 
+	switch event.State {
+	case zk.StateConnectedReadOnly:
+		atomic.StoreInt32(&c.readOnly, 1)
+	case zk.StateHasSession, zk.StateDisconnected:
+		atomic.StoreInt32(&c.readOnly, 0)
+	}
+
 	if event.State == zk.StateConnected {
 
 		atomic.StoreInt32(&c.connected, ConnConnected)
@@ -144,5 +175,6 @@ func (c *connectionState) processEvent(event zk.Event) {
 		}
 	}
 
+	c.stateManager.processEvent(event)
 	c.Fire(event)
 }
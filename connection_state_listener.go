@@ -0,0 +1,214 @@
+package curator
+
+import (
+	"sync"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+type ConnectionState int
+
+const (
+	ConnectionStateConnected   ConnectionState = 1
+	ConnectionStateSuspended   ConnectionState = 2
+	ConnectionStateReconnected ConnectionState = 3
+	ConnectionStateLost        ConnectionState = 4
+	ConnectionStateReadOnly    ConnectionState = 5
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case ConnectionStateConnected:
+		return "ConnectionStateConnected"
+	case ConnectionStateSuspended:
+		return "ConnectionStateSuspended"
+	case ConnectionStateReconnected:
+		return "ConnectionStateReconnected"
+	case ConnectionStateLost:
+		return "ConnectionStateLost"
+	case ConnectionStateReadOnly:
+		return "ConnectionStateReadOnly"
+	}
+	return "unknown"
+}
+
+func (s ConnectionState) IsConnected() bool {
+	return s == ConnectionStateConnected || s == ConnectionStateReconnected
+}
+
+type ConnectionStateListener interface {
+	StateChanged(client *ZookeeperClient, state ConnectionState)
+}
+
+type ConnectionStateListenable interface {
+	AddListener(listener ConnectionStateListener)
+	RemoveListener(listener ConnectionStateListener)
+}
+
+// ConnectionStateErrorPolicy decides which connection states revoke the
+// resources held by recipes such as LeaderSelector.
+type ConnectionStateErrorPolicy int
+
+const (
+	// StandardConnectionStateErrorPolicy treats both suspended and lost
+	// connections as errors.
+	StandardConnectionStateErrorPolicy ConnectionStateErrorPolicy = 0
+	// SessionConnectionStateErrorPolicy only treats a lost session as error.
+	SessionConnectionStateErrorPolicy ConnectionStateErrorPolicy = 1
+)
+
+func (p ConnectionStateErrorPolicy) IsErrorState(state ConnectionState) bool {
+	if p == SessionConnectionStateErrorPolicy {
+		return state == ConnectionStateLost
+	}
+	return state == ConnectionStateSuspended || state == ConnectionStateLost
+}
+
+// connectionStateTracker translates the session events of zk into
+// ConnectionState, it is not safe for concurrent use.
+type connectionStateTracker struct {
+	connected bool
+	suspended bool
+	readOnly  bool
+}
+
+func (t *connectionStateTracker) translate(event zk.Event) (ConnectionState, bool) {
+	if event.Type != zk.EventSession {
+		return 0, false
+	}
+
+	switch event.State {
+	case zk.StateConnectedReadOnly:
+		t.connected = true
+		t.suspended = false
+		t.readOnly = true
+		return ConnectionStateReadOnly, true
+	case zk.StateHasSession:
+		if !t.connected {
+			t.connected = true
+			t.suspended = false
+			return ConnectionStateConnected, true
+		}
+		if t.suspended || t.readOnly {
+			t.suspended = false
+			t.readOnly = false
+			return ConnectionStateReconnected, true
+		}
+	case zk.StateDisconnected:
+		if t.connected && !t.suspended {
+			t.suspended = true
+			t.readOnly = false
+			return ConnectionStateSuspended, true
+		}
+	case zk.StateExpired:
+		if t.connected {
+			t.suspended = true
+			return ConnectionStateLost, true
+		}
+	}
+	return 0, false
+}
+
+const connectionStateQueueSize = 25
+
+// connectionStateManager delivers the connection states to the listeners in
+// order on its own goroutine, so slow listeners never block the zk event loop.
+type connectionStateManager struct {
+	client    *ZookeeperClient
+	mutex     sync.Mutex
+	tracker   connectionStateTracker
+	listeners []ConnectionStateListener
+	queue     chan ConnectionState
+	quit      chan struct{}
+}
+
+var _ ConnectionStateListenable = &connectionStateManager{}
+
+func newConnectionStateManager() *connectionStateManager {
+	return &connectionStateManager{}
+}
+
+func (m *connectionStateManager) start(client *ZookeeperClient) {
+	m.mutex.Lock()
+	m.client = client
+	m.tracker = connectionStateTracker{}
+	m.queue = make(chan ConnectionState, connectionStateQueueSize)
+	m.quit = make(chan struct{})
+	m.mutex.Unlock()
+
+	go m.dispatch(m.queue, m.quit)
+}
+
+// close does not wait for the dispatcher, so a listener may close the client
+// from StateChanged, e.g. on ConnectionStateLost. No listener is called once
+// the running one returns.
+func (m *connectionStateManager) close() {
+	m.mutex.Lock()
+	quit := m.quit
+	m.queue, m.quit = nil, nil
+	m.mutex.Unlock()
+
+	if quit != nil {
+		close(quit)
+	}
+}
+
+func (m *connectionStateManager) AddListener(listener ConnectionStateListener) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.listeners = append(m.listeners, listener)
+}
+
+func (m *connectionStateManager) RemoveListener(listener ConnectionStateListener) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for i, v := range m.listeners {
+		if v == listener {
+			m.listeners = append(m.listeners[:i:i], m.listeners[i+1:]...)
+			break
+		}
+	}
+}
+
+func (m *connectionStateManager) processEvent(event zk.Event) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.queue == nil {
+		return
+	}
+	state, ok := m.tracker.translate(event)
+	if !ok {
+		return
+	}
+
+	select {
+	case m.queue <- state:
+	default:
+		Log.Warnln("curator: connection state queue is full, drop state:", state)
+	}
+}
+
+func (m *connectionStateManager) dispatch(queue <-chan ConnectionState, quit <-chan struct{}) {
+	for {
+		select {
+		case <-quit:
+			return
+		case state := <-queue:
+			Log.Infoln("curator: connection state changed:", state)
+
+			m.mutex.Lock()
+			listeners := append([]ConnectionStateListener(nil), m.listeners...)
+			m.mutex.Unlock()
+
+			for _, listener := range listeners {
+				select {
+				case <-quit:
+					return
+				default:
+				}
+				listener.StateChanged(m.client, state)
+			}
+		}
+	}
+}
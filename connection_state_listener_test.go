@@ -0,0 +1,142 @@
+package curator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestConnectionStateTracker(t *testing.T) {
+	tracker := connectionStateTracker{}
+	events := []zk.State{
+		zk.StateConnecting,
+		zk.StateConnected,
+		zk.StateHasSession,
+		zk.StateDisconnected,
+		zk.StateConnecting,
+		zk.StateHasSession,
+		zk.StateDisconnected,
+		zk.StateExpired,
+		zk.StateHasSession,
+		zk.StateConnectedReadOnly,
+		zk.StateHasSession,
+		zk.StateConnectedReadOnly,
+		zk.StateDisconnected,
+		zk.StateConnecting,
+		zk.StateHasSession,
+	}
+	expected := []ConnectionState{
+		ConnectionStateConnected,
+		ConnectionStateSuspended,
+		ConnectionStateReconnected,
+		ConnectionStateSuspended,
+		ConnectionStateLost,
+		ConnectionStateReconnected,
+		ConnectionStateReadOnly,
+		ConnectionStateReconnected,
+		ConnectionStateReadOnly,
+		ConnectionStateSuspended,
+		ConnectionStateReconnected,
+	}
+
+	var states []ConnectionState
+	for _, state := range events {
+		if s, ok := tracker.translate(zk.Event{Type: zk.EventSession, State: state}); ok {
+			states = append(states, s)
+		}
+	}
+	if len(states) != len(expected) {
+		t.Fatal("unexpected states:", states)
+	}
+	for i := range expected {
+		if states[i] != expected[i] {
+			t.Fatal("unexpected states:", states)
+		}
+	}
+}
+
+func TestConnectionStateErrorPolicy(t *testing.T) {
+	if !StandardConnectionStateErrorPolicy.IsErrorState(ConnectionStateSuspended) ||
+		!StandardConnectionStateErrorPolicy.IsErrorState(ConnectionStateLost) {
+		t.Fatal("standard policy must treat suspended and lost as error")
+	}
+	if SessionConnectionStateErrorPolicy.IsErrorState(ConnectionStateSuspended) ||
+		!SessionConnectionStateErrorPolicy.IsErrorState(ConnectionStateLost) {
+		t.Fatal("session policy must only treat lost as error")
+	}
+}
+
+type mockConnectionStateListener struct {
+	c chan ConnectionState
+}
+
+func (m *mockConnectionStateListener) StateChanged(client *ZookeeperClient, state ConnectionState) {
+	m.c <- state
+}
+
+func TestZookeeperClient_ConnectionStateListenable(t *testing.T) {
+	client, err := NewZookeeperClientBuidler().
+		WithZookeeperFactory(DefaultZookeeperFactory).
+		WithEnsembleProvider(NewFixedEnsembleProvider(testServers)).
+		WithRetryPolicy(NewRetryForever(500 * time.Millisecond)).
+		WithSessionTimeout(3 * time.Second).
+		WithConnectionTimeout(1 * time.Second).
+		Build()
+	if err != nil {
+		t.Fatal("failed to Build, err:", err)
+	}
+
+	listener := &mockConnectionStateListener{c: make(chan ConnectionState, 10)}
+	client.GetConnectionStateListenable().AddListener(listener)
+	if err := client.Start(); err != nil {
+		t.Fatal("failed to client.Start, err:", err)
+	}
+	defer client.Close()
+
+	select {
+	case state := <-listener.c:
+		if state != ConnectionStateConnected {
+			t.Fatal("unexpected state:", state)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("deadline waiting for ConnectionStateConnected")
+	}
+	client.GetConnectionStateListenable().RemoveListener(listener)
+}
+
+type closingConnectionStateListener struct {
+	closed chan struct{}
+}
+
+func (c *closingConnectionStateListener) StateChanged(client *ZookeeperClient, state ConnectionState) {
+	client.Close()
+	close(c.closed)
+}
+
+func TestZookeeperClient_CloseFromListener(t *testing.T) {
+	client, err := NewZookeeperClientBuidler().
+		WithZookeeperFactory(DefaultZookeeperFactory).
+		WithEnsembleProvider(NewFixedEnsembleProvider(testServers)).
+		WithRetryPolicy(NewRetryForever(500 * time.Millisecond)).
+		WithSessionTimeout(3 * time.Second).
+		WithConnectionTimeout(1 * time.Second).
+		Build()
+	if err != nil {
+		t.Fatal("failed to Build, err:", err)
+	}
+
+	// usually done on ConnectionStateLost, the first state is enough here
+	listener := &closingConnectionStateListener{closed: make(chan struct{})}
+	client.GetConnectionStateListenable().AddListener(listener)
+	if err := client.Start(); err != nil {
+		t.Fatal("failed to client.Start, err:", err)
+	}
+	defer client.Close()
+
+	select {
+	case <-listener.closed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("client.Close deadlocked in the listener")
+	}
+}
@@ -0,0 +1,164 @@
+package curator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultDNSPollingInterval = 1 * time.Minute
+	DefaultDNSResolveTimeout  = 10 * time.Second
+)
+
+// DNSResolver is satisfied by *net.Resolver.
+type DNSResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// DNSEnsembleProvider resolves the ensemble either from SRV records such as
+// _zookeeper._tcp.example.com or from the A/AAAA records of a host with a
+// fixed client port.
+type DNSEnsembleProvider struct {
+	start            int32
+	service          string
+	proto            string
+	name             string
+	port             int
+	pollingInterval  time.Duration
+	resolver         DNSResolver
+	connectionString atomic.Value
+	quit             chan struct{}
+	wg               sync.WaitGroup
+}
+
+var _ EnsembleProvider = &DNSEnsembleProvider{}
+
+// NewDNSSRVEnsembleProvider looks up _service._proto.name, e.g. "zookeeper",
+// "tcp" and "example.com".
+func NewDNSSRVEnsembleProvider(service, proto, name string, pollingInterval time.Duration) *DNSEnsembleProvider {
+	return newDNSEnsembleProvider(service, proto, name, 0, pollingInterval)
+}
+
+// NewDNSEnsembleProvider looks up the addresses of host and joins them with port.
+func NewDNSEnsembleProvider(host string, port int, pollingInterval time.Duration) *DNSEnsembleProvider {
+	return newDNSEnsembleProvider("", "", host, port, pollingInterval)
+}
+
+func newDNSEnsembleProvider(service, proto, name string, port int, pollingInterval time.Duration) *DNSEnsembleProvider {
+	if pollingInterval <= 0 {
+		pollingInterval = DefaultDNSPollingInterval
+	}
+	d := &DNSEnsembleProvider{
+		service:         service,
+		proto:           proto,
+		name:            name,
+		port:            port,
+		pollingInterval: pollingInterval,
+		resolver:        net.DefaultResolver,
+	}
+	d.connectionString.Store("")
+	return d
+}
+
+func (d *DNSEnsembleProvider) WithResolver(resolver DNSResolver) *DNSEnsembleProvider {
+	d.resolver = resolver
+	return d
+}
+
+func (d *DNSEnsembleProvider) Start() error {
+	if !atomic.CompareAndSwapInt32(&d.start, 0, 1) {
+		return errors.New("curator: DNSEnsembleProvider already started")
+	}
+
+	if err := d.poll(); err != nil {
+		atomic.StoreInt32(&d.start, 0)
+		return err
+	}
+
+	d.quit = make(chan struct{})
+	d.wg.Add(1)
+	go d.pollLoop()
+	return nil
+}
+
+func (d *DNSEnsembleProvider) Close() error {
+	if atomic.CompareAndSwapInt32(&d.start, 1, 0) {
+		close(d.quit)
+		d.wg.Wait()
+	}
+	return nil
+}
+
+func (d *DNSEnsembleProvider) GetConnectionString() string {
+	return d.connectionString.Load().(string)
+}
+
+func (d *DNSEnsembleProvider) pollLoop() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.pollingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.quit:
+			return
+		case <-ticker.C:
+			if err := d.poll(); err != nil {
+				Log.Warnln("curator: DNSEnsembleProvider failed to resolve, keep the last ensemble. err:", err)
+			}
+		}
+	}
+}
+
+func (d *DNSEnsembleProvider) poll() error {
+	servers, err := d.resolve()
+	if err != nil {
+		return err
+	}
+	if len(servers) == 0 {
+		return errors.New("curator: DNSEnsembleProvider resolved no servers, name: " + d.name)
+	}
+
+	sort.Strings(servers)
+	connString := strings.Join(servers, ",")
+	if connString != d.GetConnectionString() {
+		Log.Infoln("curator: DNSEnsembleProvider connection string changed to:", connString)
+		d.connectionString.Store(connString)
+	}
+	return nil
+}
+
+func (d *DNSEnsembleProvider) resolve() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultDNSResolveTimeout)
+	defer cancel()
+
+	var servers []string
+	if d.service != "" {
+		_, records, err := d.resolver.LookupSRV(ctx, d.service, d.proto, d.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			host := strings.TrimSuffix(record.Target, ".")
+			servers = append(servers, net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+		}
+	} else {
+		addrs, err := d.resolver.LookupHost(ctx, d.name)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			servers = append(servers, net.JoinHostPort(addr, strconv.Itoa(d.port)))
+		}
+	}
+	return servers, nil
+}
@@ -0,0 +1,82 @@
+package curator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeDNSResolver struct {
+	mutex sync.Mutex
+	srv   []*net.SRV
+	hosts []string
+	err   error
+}
+
+func (f *fakeDNSResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return "_" + service + "._" + proto + "." + name, f.srv, f.err
+}
+
+func (f *fakeDNSResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.hosts, f.err
+}
+
+func TestDNSEnsembleProvider_SRV(t *testing.T) {
+	resolver := &fakeDNSResolver{
+		srv: []*net.SRV{
+			{Target: "zk2.example.com.", Port: 2181},
+			{Target: "zk1.example.com.", Port: 2181},
+		},
+	}
+	provider := NewDNSSRVEnsembleProvider("zookeeper", "tcp", "example.com", 10*time.Millisecond).WithResolver(resolver)
+	if err := provider.Start(); err != nil {
+		t.Fatal("failed to provider.Start, err:", err)
+	}
+	defer provider.Close()
+
+	if connString := provider.GetConnectionString(); connString != "zk1.example.com:2181,zk2.example.com:2181" {
+		t.Fatal("unexpected connection string:", connString)
+	}
+
+	resolver.mutex.Lock()
+	resolver.err = errors.New("temporary failure")
+	resolver.mutex.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	if connString := provider.GetConnectionString(); connString != "zk1.example.com:2181,zk2.example.com:2181" {
+		t.Fatal("connection string must be kept on failure:", connString)
+	}
+
+	resolver.mutex.Lock()
+	resolver.err = nil
+	resolver.srv = []*net.SRV{{Target: "zk3.example.com.", Port: 2182}}
+	resolver.mutex.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	if connString := provider.GetConnectionString(); connString != "zk3.example.com:2182" {
+		t.Fatal("unexpected connection string:", connString)
+	}
+}
+
+func TestDNSEnsembleProvider_Host(t *testing.T) {
+	resolver := &fakeDNSResolver{hosts: []string{"10.0.0.2", "10.0.0.1", "fd00::1"}}
+	provider := NewDNSEnsembleProvider("zk.example.com", 2181, 0).WithResolver(resolver)
+	if err := provider.Start(); err != nil {
+		t.Fatal("failed to provider.Start, err:", err)
+	}
+	defer provider.Close()
+
+	if connString := provider.GetConnectionString(); connString != "10.0.0.1:2181,10.0.0.2:2181,[fd00::1]:2181" {
+		t.Fatal("unexpected connection string:", connString)
+	}
+
+	empty := NewDNSEnsembleProvider("zk.example.com", 2181, 0).WithResolver(&fakeDNSResolver{})
+	if err := empty.Start(); err == nil {
+		t.Fatal("unexpected Start result without servers")
+	}
+}
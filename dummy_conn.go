@@ -66,3 +66,7 @@ func (d dummyConn) GetACL(path string) ([]zk.ACL, *zk.Stat, error) {
 func (d dummyConn) SetACL(path string, aclv []zk.ACL, version int32) (*zk.Stat, error) {
 	return nil, d.err
 }
+
+func (d dummyConn) Multi(ops ...interface{}) ([]zk.MultiResponse, error) {
+	return nil, d.err
+}
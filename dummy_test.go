@@ -102,3 +102,11 @@ func TestDummyConn_SetACL(t *testing.T) {
 		t.Fatal("unexpected error")
 	}
 }
+
+func TestDummyConn_Multi(t *testing.T) {
+	dummy := dummyConn{err: errTestDummy}
+	_, err := dummy.Multi()
+	if err != errTestDummy {
+		t.Fatal("unexpected error")
+	}
+}
@@ -0,0 +1,35 @@
+package curator
+
+import (
+	"sync/atomic"
+)
+
+// DynamicEnsembleProvider starts from a fixed connection string which can be
+// replaced later, e.g. by EnsembleTracker.
+type DynamicEnsembleProvider struct {
+	connectionString atomic.Value
+}
+
+var _ UpdatableEnsembleProvider = &DynamicEnsembleProvider{}
+
+func NewDynamicEnsembleProvider(connString string) *DynamicEnsembleProvider {
+	d := &DynamicEnsembleProvider{}
+	d.connectionString.Store(connString)
+	return d
+}
+
+func (d *DynamicEnsembleProvider) Start() error {
+	return nil
+}
+
+func (d *DynamicEnsembleProvider) Close() error {
+	return nil
+}
+
+func (d *DynamicEnsembleProvider) GetConnectionString() string {
+	return d.connectionString.Load().(string)
+}
+
+func (d *DynamicEnsembleProvider) SetConnectionString(connString string) {
+	d.connectionString.Store(connString)
+}
@@ -5,3 +5,15 @@ type EnsembleProvider interface {
 	Close() error
 	GetConnectionString() string
 }
+
+type UpdatableEnsembleProvider interface {
+	EnsembleProvider
+	SetConnectionString(connString string)
+}
+
+// ConnectionObserver may be implemented by an EnsembleProvider to be told
+// whether connecting to the ensemble of connString succeeded.
+type ConnectionObserver interface {
+	ConnectionSucceeded(connString string)
+	ConnectionFailed(connString string)
+}
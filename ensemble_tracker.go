@@ -0,0 +1,108 @@
+package curator
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+const ZookeeperConfigNode = "/zookeeper/config"
+
+// EnsembleTracker watches the dynamic config of ZooKeeper 3.5 and feeds the
+// ensemble into the provider, the client picks it up on the next reconnect.
+type EnsembleTracker struct {
+	start    int32
+	client   *ZookeeperClient
+	provider UpdatableEnsembleProvider
+	quit     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewEnsembleTracker(client *ZookeeperClient, provider UpdatableEnsembleProvider) *EnsembleTracker {
+	// the config node is outside of any namespace
+	if client.parent != nil {
+		client = client.parent
+	}
+	return &EnsembleTracker{
+		client:   client,
+		provider: provider,
+	}
+}
+
+func (e *EnsembleTracker) Start() error {
+	if !atomic.CompareAndSwapInt32(&e.start, 0, 1) {
+		return errors.New("curator: EnsembleTracker already started")
+	}
+
+	e.quit = make(chan struct{})
+	e.wg.Add(1)
+	go e.watchConfig()
+	return nil
+}
+
+func (e *EnsembleTracker) Close() error {
+	if !atomic.CompareAndSwapInt32(&e.start, 1, 0) {
+		return errors.New("curator: EnsembleTracker already closed")
+	}
+
+	close(e.quit)
+	e.wg.Wait()
+	return nil
+}
+
+func (e *EnsembleTracker) watchConfig() {
+	Log.Infoln("curator: start EnsembleTracker.watchConfig")
+	defer func() {
+		Log.Infoln("curator: stop EnsembleTracker.watchConfig")
+		e.wg.Done()
+	}()
+
+	for {
+		data, _, eventChan, err := e.client.GetW(ZookeeperConfigNode)
+		if err == zk.ErrNoNode {
+			// servers before 3.5 have no dynamic config
+			var exist bool
+			exist, _, eventChan, err = e.client.ExistsW(ZookeeperConfigNode)
+			if err == nil && exist {
+				continue
+			}
+		} else if err == nil {
+			e.update(data)
+		}
+
+		if err != nil {
+			Log.Errorln("curator: EnsembleTracker failed to watch config, err:", err)
+			select {
+			case <-e.quit:
+				return
+			case <-time.After(cacheRetryInterval):
+			}
+			continue
+		}
+
+		select {
+		case <-e.quit:
+			return
+		case <-eventChan:
+		}
+	}
+}
+
+func (e *EnsembleTracker) update(data []byte) {
+	if len(data) == 0 {
+		return
+	}
+
+	connString, err := parseEnsembleConfig(data)
+	if err != nil {
+		Log.Warnln("curator: EnsembleTracker failed to parse config, err:", err)
+		return
+	}
+	if connString != e.provider.GetConnectionString() {
+		Log.Infoln("curator: EnsembleTracker connection string changed to:", connString)
+		e.provider.SetConnectionString(connString)
+	}
+}
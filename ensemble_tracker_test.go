@@ -0,0 +1,41 @@
+package curator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestEnsembleTracker(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal("failed to newZookeeperClient, err:", err)
+	}
+	defer client.Close()
+
+	data, _, err := client.Get(ZookeeperConfigNode)
+	if err == zk.ErrNoNode || len(data) == 0 {
+		t.Skip("server has no dynamic config")
+	} else if err != nil {
+		t.Fatal("failed to client.Get, err:", err)
+	}
+	expected, err := parseEnsembleConfig(data)
+	if err != nil {
+		t.Fatal("failed to parseEnsembleConfig, err:", err)
+	}
+
+	provider := NewDynamicEnsembleProvider(testServers)
+	tracker := NewEnsembleTracker(client, provider)
+	if err := tracker.Start(); err != nil {
+		t.Fatal("failed to tracker.Start, err:", err)
+	}
+	defer tracker.Close()
+
+	for i := 0; i < 10 && provider.GetConnectionString() != expected; i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if connString := provider.GetConnectionString(); connString != expected {
+		t.Fatal("unexpected connection string:", connString, "expected:", expected)
+	}
+}
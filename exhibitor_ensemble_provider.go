@@ -1,3 +1,239 @@
 package curator
 
-// TODO(eahydra): Implement Netflix/exhibitor client
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultExhibitorRESTURIPath     = "/exhibitor/v1/cluster/list"
+	DefaultExhibitorPollingInterval = 5 * time.Minute
+)
+
+type Exhibitors struct {
+	Hosts                  []string
+	RESTPort               int
+	BackupConnectionString string
+}
+
+type exhibitorClusterList struct {
+	Servers []string `json:"servers"`
+	Port    int      `json:"port"`
+}
+
+type ExhibitorEnsembleProvider struct {
+	start            int32
+	mutex            sync.Mutex
+	exhibitors       Exhibitors
+	restURIPath      string
+	pollingInterval  time.Duration
+	retryPolicy      RetryPolicy
+	httpClient       *http.Client
+	connectionString atomic.Value
+	quit             chan struct{}
+	wg               sync.WaitGroup
+}
+
+var _ EnsembleProvider = &ExhibitorEnsembleProvider{}
+
+func NewExhibitorEnsembleProvider(exhibitors Exhibitors, restURIPath string, pollingInterval time.Duration, retryPolicy RetryPolicy) *ExhibitorEnsembleProvider {
+	if restURIPath == "" {
+		restURIPath = DefaultExhibitorRESTURIPath
+	}
+	if pollingInterval <= 0 {
+		pollingInterval = DefaultExhibitorPollingInterval
+	}
+	e := &ExhibitorEnsembleProvider{
+		exhibitors:      exhibitors,
+		restURIPath:     restURIPath,
+		pollingInterval: pollingInterval,
+		retryPolicy:     retryPolicy,
+		httpClient:      &http.Client{Timeout: 30 * time.Second},
+	}
+	e.connectionString.Store("")
+	return e
+}
+
+func (e *ExhibitorEnsembleProvider) WithHTTPClient(client *http.Client) *ExhibitorEnsembleProvider {
+	e.httpClient = client
+	return e
+}
+
+func (e *ExhibitorEnsembleProvider) Start() error {
+	if !atomic.CompareAndSwapInt32(&e.start, 0, 1) {
+		return errors.New("curator: ExhibitorEnsembleProvider already started")
+	}
+
+	e.quit = make(chan struct{})
+	e.poll()
+	if e.GetConnectionString() == "" {
+		atomic.StoreInt32(&e.start, 0)
+		return errors.New("curator: could not get initial ensemble from exhibitors")
+	}
+
+	e.wg.Add(1)
+	go e.pollLoop()
+	return nil
+}
+
+func (e *ExhibitorEnsembleProvider) Close() error {
+	if atomic.CompareAndSwapInt32(&e.start, 1, 0) {
+		close(e.quit)
+		e.wg.Wait()
+	}
+	return nil
+}
+
+func (e *ExhibitorEnsembleProvider) GetConnectionString() string {
+	return e.connectionString.Load().(string)
+}
+
+func (e *ExhibitorEnsembleProvider) getExhibitors() Exhibitors {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.exhibitors
+}
+
+func (e *ExhibitorEnsembleProvider) pollLoop() {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.pollingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.quit:
+			return
+		case <-ticker.C:
+			e.poll()
+		}
+	}
+}
+
+func (e *ExhibitorEnsembleProvider) poll() {
+	exhibitors := e.getExhibitors()
+
+	var servers []string
+	hosts, port, err := e.queryExhibitors(exhibitors)
+	if err != nil || len(hosts) == 0 {
+		Log.Warnln("curator: failed to query exhibitors, using backup connection string. err:", err)
+		hosts, servers = parseBackupConnectionString(exhibitors.BackupConnectionString)
+	} else {
+		for _, host := range hosts {
+			servers = append(servers, net.JoinHostPort(host, strconv.Itoa(port)))
+		}
+	}
+	if len(hosts) == 0 {
+		return
+	}
+
+	connString := strings.Join(servers, ",")
+	if connString != e.GetConnectionString() {
+		Log.Infoln("curator: ExhibitorEnsembleProvider connection string changed to:", connString)
+	}
+
+	e.mutex.Lock()
+	e.exhibitors = Exhibitors{
+		Hosts:                  hosts,
+		RESTPort:               exhibitors.RESTPort,
+		BackupConnectionString: exhibitors.BackupConnectionString,
+	}
+	e.mutex.Unlock()
+	e.connectionString.Store(connString)
+}
+
+func (e *ExhibitorEnsembleProvider) queryExhibitors(exhibitors Exhibitors) ([]string, int, error) {
+	if len(exhibitors.Hosts) == 0 {
+		return nil, 0, errors.New("curator: no exhibitor hosts")
+	}
+
+	hosts := make([]string, len(exhibitors.Hosts))
+	for i, v := range rand.Perm(len(exhibitors.Hosts)) {
+		hosts[i] = exhibitors.Hosts[v]
+	}
+
+	var (
+		startTime = time.Now()
+		sleeper   = RetrySleeperFunc(e.sleep)
+		err       error
+	)
+	for count := 0; ; count++ {
+		host := hosts[count%len(hosts)]
+		var list *exhibitorClusterList
+		list, err = e.queryExhibitor(host, exhibitors.RESTPort)
+		if err == nil {
+			return list.Servers, list.Port, nil
+		}
+		Log.Warnln("curator: failed to query exhibitor, host:", host, "err:", err)
+
+		if e.retryPolicy == nil || !e.retryPolicy.AllowRetry(count, time.Since(startTime), sleeper) {
+			break
+		}
+	}
+	return nil, 0, err
+}
+
+func (e *ExhibitorEnsembleProvider) queryExhibitor(host string, port int) (*exhibitorClusterList, error) {
+	uri := fmt.Sprintf("http://%s%s", net.JoinHostPort(host, strconv.Itoa(port)), e.restURIPath)
+	resp, err := e.httpClient.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("curator: exhibitor returned unexpected status %d", resp.StatusCode)
+	}
+
+	list := &exhibitorClusterList{}
+	if err := json.Unmarshal(body, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (e *ExhibitorEnsembleProvider) sleep(duration time.Duration) error {
+	select {
+	case <-e.quit:
+		return errors.New("curator: ExhibitorEnsembleProvider had been closed")
+	case <-time.After(duration):
+		return nil
+	}
+}
+
+// parseBackupConnectionString returns the hosts and the servers of connString,
+// the servers keep their own ports.
+func parseBackupConnectionString(connString string) (hosts []string, servers []string) {
+	for _, server := range strings.Split(connString, ",") {
+		server = strings.TrimSpace(server)
+		if server == "" {
+			continue
+		}
+		host, portStr, err := net.SplitHostPort(server)
+		if err != nil {
+			Log.Warnln("curator: invalid backup server:", server, "err:", err)
+			continue
+		}
+		if _, err := strconv.Atoi(portStr); err != nil {
+			Log.Warnln("curator: invalid backup server port:", server, "err:", err)
+			continue
+		}
+		hosts = append(hosts, host)
+		servers = append(servers, net.JoinHostPort(host, portStr))
+	}
+	return
+}
@@ -0,0 +1,84 @@
+package curator
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newExhibitorTestServer(t *testing.T, body *atomic.Value) (*httptest.Server, Exhibitors) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != DefaultExhibitorRESTURIPath {
+			http.NotFound(w, r)
+			return
+		}
+		v := body.Load().(string)
+		if v == "" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(v))
+	}))
+
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(portStr)
+	return server, Exhibitors{Hosts: []string{host}, RESTPort: port}
+}
+
+func TestExhibitorEnsembleProvider_Start(t *testing.T) {
+	var body atomic.Value
+	body.Store(`{"servers":["127.0.0.1"],"port":2181}`)
+	server, exhibitors := newExhibitorTestServer(t, &body)
+	defer server.Close()
+
+	provider := NewExhibitorEnsembleProvider(exhibitors, "", 50*time.Millisecond, NewRetryNTimes(0, 0))
+	if err := provider.Start(); err != nil {
+		t.Fatal("failed to provider.Start, err:", err)
+	}
+	defer provider.Close()
+
+	if s := provider.GetConnectionString(); s != "127.0.0.1:2181" {
+		t.Fatal("unexpected connection string:", s)
+	}
+
+	body.Store(`{"servers":["127.0.0.1"],"port":2182}`)
+	deadline := time.After(1 * time.Second)
+	for provider.GetConnectionString() != "127.0.0.1:2182" {
+		select {
+		case <-deadline:
+			t.Fatal("connection string not updated, got:", provider.GetConnectionString())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestExhibitorEnsembleProvider_Backup(t *testing.T) {
+	var body atomic.Value
+	body.Store("")
+	server, exhibitors := newExhibitorTestServer(t, &body)
+	defer server.Close()
+
+	provider := NewExhibitorEnsembleProvider(exhibitors, "", time.Hour, NewRetryNTimes(0, 0))
+	if err := provider.Start(); err == nil {
+		provider.Close()
+		t.Fatal("Start must fail without exhibitors and backup")
+	}
+
+	exhibitors.BackupConnectionString = "10.0.0.1:2181,10.0.0.2:2182"
+	provider = NewExhibitorEnsembleProvider(exhibitors, "", time.Hour, NewRetryNTimes(1, 10*time.Millisecond))
+	if err := provider.Start(); err != nil {
+		t.Fatal("failed to provider.Start, err:", err)
+	}
+	defer provider.Close()
+
+	if s := provider.GetConnectionString(); s != exhibitors.BackupConnectionString {
+		t.Fatal("unexpected connection string:", s)
+	}
+}
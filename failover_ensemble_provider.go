@@ -0,0 +1,208 @@
+package curator
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultFailoverMaxFailures   = 3
+	DefaultFailoverProbeInterval = 30 * time.Second
+	DefaultFailoverDialTimeout   = 3 * time.Second
+)
+
+// FailoverEnsembleProvider wraps several providers in priority order, e.g. a
+// primary and a standby ensemble. It moves to the next provider once
+// maxFailures connection attempts failed in a row, or the attempts kept
+// failing for failoverAfter, and fails back to a provider of higher priority
+// as soon as its health check passes again. The client picks up the new
+// connection string on its next connection event or timeout check.
+type FailoverEnsembleProvider struct {
+	start         int32
+	providers     []EnsembleProvider
+	maxFailures   int
+	failoverAfter time.Duration
+	probeInterval time.Duration
+	healthCheck   func(connString string) bool
+	mutex         sync.Mutex
+	current       int
+	failures      int
+	firstFailure  time.Time
+	quit          chan struct{}
+	wg            sync.WaitGroup
+}
+
+var (
+	_ EnsembleProvider   = &FailoverEnsembleProvider{}
+	_ ConnectionObserver = &FailoverEnsembleProvider{}
+)
+
+// NewFailoverEnsembleProvider takes the providers with the preferred one
+// first. A zero maxFailures or failoverAfter disables that condition.
+func NewFailoverEnsembleProvider(providers []EnsembleProvider, maxFailures int, failoverAfter time.Duration) *FailoverEnsembleProvider {
+	return &FailoverEnsembleProvider{
+		providers:     providers,
+		maxFailures:   maxFailures,
+		failoverAfter: failoverAfter,
+		probeInterval: DefaultFailoverProbeInterval,
+		healthCheck:   dialEnsemble,
+	}
+}
+
+func (f *FailoverEnsembleProvider) WithProbeInterval(probeInterval time.Duration) *FailoverEnsembleProvider {
+	f.probeInterval = probeInterval
+	return f
+}
+
+// WithHealthCheck replaces the default check, which succeeds when any server
+// of the connection string accepts a TCP connection.
+func (f *FailoverEnsembleProvider) WithHealthCheck(healthCheck func(connString string) bool) *FailoverEnsembleProvider {
+	f.healthCheck = healthCheck
+	return f
+}
+
+func (f *FailoverEnsembleProvider) Start() error {
+	if len(f.providers) == 0 {
+		return errors.New("curator: FailoverEnsembleProvider needs at least one provider")
+	}
+	if !atomic.CompareAndSwapInt32(&f.start, 0, 1) {
+		return errors.New("curator: FailoverEnsembleProvider already started")
+	}
+
+	for i, provider := range f.providers {
+		if err := provider.Start(); err != nil {
+			for _, started := range f.providers[:i] {
+				started.Close()
+			}
+			atomic.StoreInt32(&f.start, 0)
+			return err
+		}
+	}
+
+	f.quit = make(chan struct{})
+	if len(f.providers) > 1 && f.probeInterval > 0 {
+		f.wg.Add(1)
+		go f.probeLoop()
+	}
+	return nil
+}
+
+func (f *FailoverEnsembleProvider) Close() error {
+	if !atomic.CompareAndSwapInt32(&f.start, 1, 0) {
+		return errors.New("curator: FailoverEnsembleProvider not started")
+	}
+
+	close(f.quit)
+	f.wg.Wait()
+	for _, provider := range f.providers {
+		provider.Close()
+	}
+	return nil
+}
+
+func (f *FailoverEnsembleProvider) GetConnectionString() string {
+	f.mutex.Lock()
+	current := f.current
+	f.mutex.Unlock()
+	return f.providers[current].GetConnectionString()
+}
+
+// Current returns the index of the provider in use.
+func (f *FailoverEnsembleProvider) Current() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.current
+}
+
+func (f *FailoverEnsembleProvider) ConnectionSucceeded(connString string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if connString == f.providers[f.current].GetConnectionString() {
+		f.failures = 0
+		f.firstFailure = time.Time{}
+	}
+}
+
+func (f *FailoverEnsembleProvider) ConnectionFailed(connString string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	// outcomes of a connection string which was already replaced do not count
+	if connString != f.providers[f.current].GetConnectionString() {
+		return
+	}
+
+	now := time.Now()
+	f.failures++
+	if f.firstFailure.IsZero() {
+		f.firstFailure = now
+	}
+
+	if (f.maxFailures > 0 && f.failures >= f.maxFailures) ||
+		(f.failoverAfter > 0 && now.Sub(f.firstFailure) >= f.failoverAfter) {
+		next := (f.current + 1) % len(f.providers)
+		Log.Warnln("curator: FailoverEnsembleProvider failover from", f.current, "to", next, "after", f.failures, "failures")
+		f.switchTo(next)
+	}
+}
+
+func (f *FailoverEnsembleProvider) switchTo(index int) {
+	f.current = index
+	f.failures = 0
+	f.firstFailure = time.Time{}
+}
+
+func (f *FailoverEnsembleProvider) probeLoop() {
+	Log.Infoln("curator: start FailoverEnsembleProvider.probeLoop")
+	defer func() {
+		Log.Infoln("curator: stop FailoverEnsembleProvider.probeLoop")
+		f.wg.Done()
+	}()
+
+	for {
+		select {
+		case <-f.quit:
+			return
+		case <-time.After(f.probeInterval):
+		}
+		f.probe()
+	}
+}
+
+// probe fails back to the provider of the highest priority that is healthy.
+func (f *FailoverEnsembleProvider) probe() {
+	current := f.Current()
+	for i := 0; i < current; i++ {
+		if !f.healthCheck(f.providers[i].GetConnectionString()) {
+			continue
+		}
+
+		f.mutex.Lock()
+		if i < f.current {
+			Log.Infoln("curator: FailoverEnsembleProvider failback from", f.current, "to", i)
+			f.switchTo(i)
+		}
+		f.mutex.Unlock()
+		return
+	}
+}
+
+func dialEnsemble(connString string) bool {
+	for _, server := range strings.Split(connString, ",") {
+		server = strings.TrimSpace(server)
+		if server == "" {
+			continue
+		}
+		conn, err := net.DialTimeout("tcp", server, DefaultFailoverDialTimeout)
+		if err == nil {
+			conn.Close()
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package curator
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFailoverEnsembleProvider_Failover(t *testing.T) {
+	primary := NewDynamicEnsembleProvider("primary:2181")
+	standby := NewDynamicEnsembleProvider("standby:2181")
+	var primaryHealthy int32
+	provider := NewFailoverEnsembleProvider([]EnsembleProvider{primary, standby}, 3, 0).
+		WithProbeInterval(10 * time.Millisecond).
+		WithHealthCheck(func(connString string) bool {
+			return connString == "primary:2181" && atomic.LoadInt32(&primaryHealthy) == 1
+		})
+	if err := provider.Start(); err != nil {
+		t.Fatal("failed to provider.Start, err:", err)
+	}
+	defer provider.Close()
+
+	if connString := provider.GetConnectionString(); connString != "primary:2181" {
+		t.Fatal("unexpected connection string:", connString)
+	}
+
+	provider.ConnectionFailed("primary:2181")
+	provider.ConnectionFailed("primary:2181")
+	provider.ConnectionSucceeded("primary:2181")
+	provider.ConnectionFailed("primary:2181")
+	provider.ConnectionFailed("primary:2181")
+	if connString := provider.GetConnectionString(); connString != "primary:2181" {
+		t.Fatal("a success must reset the failures:", connString)
+	}
+
+	provider.ConnectionFailed("primary:2181")
+	if connString := provider.GetConnectionString(); connString != "standby:2181" {
+		t.Fatal("unexpected connection string after failover:", connString)
+	}
+
+	// late outcomes of the primary do not count against the standby
+	for i := 0; i < 3; i++ {
+		provider.ConnectionFailed("primary:2181")
+	}
+	if current := provider.Current(); current != 1 {
+		t.Fatal("unexpected current provider:", current)
+	}
+
+	atomic.StoreInt32(&primaryHealthy, 1)
+	deadline := time.Now().Add(3 * time.Second)
+	for provider.GetConnectionString() != "primary:2181" {
+		if time.Now().After(deadline) {
+			t.Fatal("provider did not fail back to the primary")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFailoverEnsembleProvider_FailoverAfter(t *testing.T) {
+	provider := NewFailoverEnsembleProvider([]EnsembleProvider{
+		NewFixedEnsembleProvider("primary:2181"),
+		NewFixedEnsembleProvider("standby:2181"),
+	}, 0, 50*time.Millisecond).WithProbeInterval(0)
+	if err := provider.Start(); err != nil {
+		t.Fatal("failed to provider.Start, err:", err)
+	}
+	defer provider.Close()
+
+	provider.ConnectionFailed("primary:2181")
+	if connString := provider.GetConnectionString(); connString != "primary:2181" {
+		t.Fatal("unexpected connection string:", connString)
+	}
+	time.Sleep(60 * time.Millisecond)
+	provider.ConnectionFailed("primary:2181")
+	if connString := provider.GetConnectionString(); connString != "standby:2181" {
+		t.Fatal("unexpected connection string after failover:", connString)
+	}
+}
+
+func TestFailoverEnsembleProvider_Client(t *testing.T) {
+	provider := NewFailoverEnsembleProvider([]EnsembleProvider{
+		NewFixedEnsembleProvider("127.0.0.1:1"),
+		NewFixedEnsembleProvider(testServers),
+	}, 2, 0).WithProbeInterval(0)
+
+	client, err := NewZookeeperClientBuidler().
+		WithZookeeperFactory(DefaultZookeeperFactory).
+		WithEnsembleProvider(provider).
+		WithRetryPolicy(NewRetryForever(500 * time.Millisecond)).
+		WithSessionTimeout(3 * time.Second).
+		WithConnectionTimeout(1 * time.Second).
+		Build()
+	if err != nil {
+		t.Fatal("failed to build client, err:", err)
+	}
+	if err := client.Start(); err != nil {
+		t.Fatal("failed to client.Start, err:", err)
+	}
+	defer client.Close()
+
+	if _, _, err := client.Exists("/"); err != nil {
+		t.Fatal("failed to reach the standby ensemble, err:", err)
+	}
+	if current := provider.Current(); current != 1 {
+		t.Fatal("unexpected current provider:", current)
+	}
+}
@@ -0,0 +1,185 @@
+package curator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const DefaultFilePollingInterval = 10 * time.Second
+
+// FileEnsembleProvider reads the ensemble from a file and polls it for
+// changes. The file holds either "host:port,host:port" or the
+// "server.N=host:peer:election;client" lines of zoo.cfg or a dynamic config.
+type FileEnsembleProvider struct {
+	start            int32
+	filename         string
+	pollingInterval  time.Duration
+	connectionString atomic.Value
+	quit             chan struct{}
+	wg               sync.WaitGroup
+}
+
+var _ EnsembleProvider = &FileEnsembleProvider{}
+
+func NewFileEnsembleProvider(filename string, pollingInterval time.Duration) *FileEnsembleProvider {
+	if pollingInterval <= 0 {
+		pollingInterval = DefaultFilePollingInterval
+	}
+	f := &FileEnsembleProvider{
+		filename:        filename,
+		pollingInterval: pollingInterval,
+	}
+	f.connectionString.Store("")
+	return f
+}
+
+func (f *FileEnsembleProvider) Start() error {
+	if !atomic.CompareAndSwapInt32(&f.start, 0, 1) {
+		return errors.New("curator: FileEnsembleProvider already started")
+	}
+
+	if err := f.poll(); err != nil {
+		atomic.StoreInt32(&f.start, 0)
+		return err
+	}
+
+	f.quit = make(chan struct{})
+	f.wg.Add(1)
+	go f.pollLoop()
+	return nil
+}
+
+func (f *FileEnsembleProvider) Close() error {
+	if atomic.CompareAndSwapInt32(&f.start, 1, 0) {
+		close(f.quit)
+		f.wg.Wait()
+	}
+	return nil
+}
+
+func (f *FileEnsembleProvider) GetConnectionString() string {
+	return f.connectionString.Load().(string)
+}
+
+func (f *FileEnsembleProvider) pollLoop() {
+	defer f.wg.Done()
+
+	ticker := time.NewTicker(f.pollingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.quit:
+			return
+		case <-ticker.C:
+			if err := f.poll(); err != nil {
+				Log.Warnln("curator: FileEnsembleProvider failed to read, keep the last ensemble. file:", f.filename, "err:", err)
+			}
+		}
+	}
+}
+
+func (f *FileEnsembleProvider) poll() error {
+	data, err := ioutil.ReadFile(f.filename)
+	if err != nil {
+		return err
+	}
+
+	connString, err := parseEnsembleConfig(data)
+	if err != nil {
+		return err
+	}
+	if connString != f.GetConnectionString() {
+		Log.Infoln("curator: FileEnsembleProvider connection string changed to:", connString)
+		f.connectionString.Store(connString)
+	}
+	return nil
+}
+
+// parseEnsembleConfig returns the connection string from either a plain
+// "host:port,host:port" list or the server lines of a zoo.cfg or dynamic
+// config, whose client port may come from a separate clientPort line.
+func parseEnsembleConfig(data []byte) (string, error) {
+	type server struct {
+		host       string
+		clientPort string
+	}
+
+	var plain []string
+	var servers []server
+	var clientPort string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		index := strings.Index(line, "=")
+		if index < 0 {
+			for _, v := range strings.Split(line, ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					plain = append(plain, v)
+				}
+			}
+			continue
+		}
+
+		key, value := strings.TrimSpace(line[:index]), strings.TrimSpace(line[index+1:])
+		switch {
+		case key == "clientPort":
+			clientPort = value
+		case strings.HasPrefix(key, "server."):
+			// host:peer:election[:role][;[clientAddress:]clientPort]
+			var client string
+			if i := strings.Index(value, ";"); i >= 0 {
+				value, client = value[:i], value[i+1:]
+			}
+			host := value
+			if strings.HasPrefix(value, "[") {
+				// an IPv6 address, e.g. [::1]:2888:3888
+				if i := strings.Index(value, "]"); i >= 0 {
+					host = value[1:i]
+				}
+			} else if i := strings.Index(value, ":"); i >= 0 {
+				host = value[:i]
+			}
+			port := client
+			if clientHost, p, err := net.SplitHostPort(client); err == nil {
+				port = p
+				if clientHost != "" && clientHost != "0.0.0.0" && clientHost != "::" {
+					host = clientHost
+				}
+			}
+			servers = append(servers, server{host: host, clientPort: port})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	for _, s := range servers {
+		port := s.clientPort
+		if port == "" {
+			port = clientPort
+		}
+		if _, err := strconv.Atoi(port); err != nil {
+			return "", errors.New("curator: no client port for server " + s.host)
+		}
+		plain = append(plain, net.JoinHostPort(s.host, port))
+	}
+
+	if len(plain) == 0 {
+		return "", errors.New("curator: no servers in ensemble config")
+	}
+	return strings.Join(plain, ","), nil
+}
@@ -0,0 +1,68 @@
+package curator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseEnsembleConfig(t *testing.T) {
+	cases := []struct {
+		data     string
+		expected string
+	}{
+		{"zk1:2181, zk2:2181\n", "zk1:2181,zk2:2181"},
+		{"# zoo.cfg\nclientPort=2181\nserver.1=zk1:2888:3888\nserver.2=zk2:2888:3888\n", "zk1:2181,zk2:2181"},
+		{"server.1=zk1:2888:3888:participant;0.0.0.0:2181\nserver.2=zk2:2888:3888:observer;2182\nversion=100000000\n", "zk1:2181,zk2:2182"},
+		{"server.1=zk1:2888:3888;10.0.0.1:2181\n", "10.0.0.1:2181"},
+		{"server.1=[::1]:2888:3888;2181\nserver.2=[fd00::2]:2888:3888:participant;[fd00::3]:2182\n", "[::1]:2181,[fd00::3]:2182"},
+	}
+	for _, c := range cases {
+		connString, err := parseEnsembleConfig([]byte(c.data))
+		if err != nil || connString != c.expected {
+			t.Fatal("unexpected connection string:", connString, "expected:", c.expected, "err:", err)
+		}
+	}
+
+	if _, err := parseEnsembleConfig([]byte("server.1=zk1:2888:3888\n")); err == nil {
+		t.Fatal("unexpected result without client port")
+	}
+}
+
+func TestFileEnsembleProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "curator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "zoo.cfg")
+	if err := ioutil.WriteFile(filename, []byte("zk1:2181"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewFileEnsembleProvider(filename, 10*time.Millisecond)
+	if err := provider.Start(); err != nil {
+		t.Fatal("failed to provider.Start, err:", err)
+	}
+	defer provider.Close()
+
+	if connString := provider.GetConnectionString(); connString != "zk1:2181" {
+		t.Fatal("unexpected connection string:", connString)
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("server.1=zk2:2888:3888;2181\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if connString := provider.GetConnectionString(); connString != "zk2:2181" {
+		t.Fatal("unexpected connection string after change:", connString)
+	}
+
+	missing := NewFileEnsembleProvider(filepath.Join(dir, "missing"), 0)
+	if err := missing.Start(); err == nil {
+		t.Fatal("unexpected Start result of missing file")
+	}
+}
@@ -0,0 +1,310 @@
+package curator
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+const latchLockName = "latch-"
+
+var (
+	ErrLeaderLatchClosed = errors.New("curator: LeaderLatch had been closed")
+)
+
+type LeaderLatchListener interface {
+	IsLeader()
+	NotLeader()
+}
+
+type Participant struct {
+	ID       string
+	IsLeader bool
+}
+
+// LeaderLatch holds the leadership from the moment it is elected until it is
+// closed or the connection is lost.
+type LeaderLatch struct {
+	start      int32
+	client     *ZookeeperClient
+	latchPath  string
+	id         string
+	aclv       []zk.ACL
+	mutex      sync.Mutex
+	ourPath    string
+	leaderShip bool
+	leaderCh   chan struct{}
+	listeners  map[LeaderLatchListener]struct{}
+	suspended  chan struct{}
+	ctx        context.Context
+	cancel     context.CancelFunc
+	quit       chan struct{}
+	wg         sync.WaitGroup
+}
+
+var _ ConnectionStateListener = &LeaderLatch{}
+
+func NewLeaderLatch(client *ZookeeperClient, latchPath string, id string, aclv []zk.ACL) *LeaderLatch {
+	return &LeaderLatch{
+		client:    client,
+		latchPath: latchPath,
+		id:        id,
+		aclv:      aclv,
+		leaderCh:  make(chan struct{}),
+		listeners: make(map[LeaderLatchListener]struct{}),
+	}
+}
+
+func (l *LeaderLatch) AddListener(listener LeaderLatchListener) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.listeners[listener] = struct{}{}
+}
+
+func (l *LeaderLatch) RemoveListener(listener LeaderLatchListener) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.listeners, listener)
+}
+
+func (l *LeaderLatch) Start() error {
+	if !atomic.CompareAndSwapInt32(&l.start, 0, 1) {
+		return errors.New("curator: LeaderLatch already started")
+	}
+
+	l.suspended = make(chan struct{}, 1)
+	l.client.GetConnectionStateListenable().AddListener(l)
+
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+	l.quit = make(chan struct{}, 1)
+	l.wg.Add(1)
+	go l.workLoop()
+	return nil
+}
+
+func (l *LeaderLatch) Close() error {
+	if !atomic.CompareAndSwapInt32(&l.start, 1, 0) {
+		return errors.New("curator: LeaderLatch already closed")
+	}
+
+	l.client.GetConnectionStateListenable().RemoveListener(l)
+	l.cancel()
+	close(l.quit)
+	l.wg.Wait()
+
+	l.mutex.Lock()
+	ourPath := l.ourPath
+	l.ourPath = ""
+	l.mutex.Unlock()
+	if ourPath != "" {
+		// the ephemeral node goes away with the session anyway
+		ctx, cancel := context.WithTimeout(context.Background(), l.client.sessionTimeout)
+		l.client.DeleteCtx(ctx, ourPath, -1)
+		cancel()
+	}
+	l.setLeadership(false)
+	return nil
+}
+
+func (l *LeaderLatch) GetID() string {
+	return l.id
+}
+
+func (l *LeaderLatch) HasLeadership() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.leaderShip
+}
+
+// Await blocks until this latch has the leadership, ctx is done or the latch
+// is closed.
+func (l *LeaderLatch) Await(ctx context.Context) error {
+	if atomic.LoadInt32(&l.start) != 1 {
+		return ErrLeaderLatchClosed
+	}
+
+	l.mutex.Lock()
+	leaderCh := l.leaderCh
+	l.mutex.Unlock()
+
+	select {
+	case <-leaderCh:
+		return nil
+	case <-l.quit:
+		return ErrLeaderLatchClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *LeaderLatch) GetParticipants() ([]Participant, error) {
+	return getParticipants(l.client, l.latchPath, latchLockName)
+}
+
+func (l *LeaderLatch) GetLeader() (Participant, error) {
+	return getLeader(l.client, l.latchPath, latchLockName)
+}
+
+func getParticipants(client *ZookeeperClient, basePath, lockName string) ([]Participant, error) {
+	children, _, err := client.Children(basePath)
+	if err != nil {
+		if err == zk.ErrNoNode {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var participants []Participant
+	for _, child := range sortLockChildren(children, lockName) {
+		data, _, err := client.Get(path.Join(basePath, child))
+		if err != nil {
+			if err == zk.ErrNoNode {
+				continue
+			}
+			return nil, err
+		}
+		participants = append(participants, Participant{ID: string(data), IsLeader: len(participants) == 0})
+	}
+	return participants, nil
+}
+
+func getLeader(client *ZookeeperClient, basePath, lockName string) (Participant, error) {
+	participants, err := getParticipants(client, basePath, lockName)
+	if err != nil || len(participants) == 0 {
+		return Participant{}, err
+	}
+	return participants[0], nil
+}
+
+func (l *LeaderLatch) StateChanged(client *ZookeeperClient, state ConnectionState) {
+	if state == ConnectionStateSuspended || state == ConnectionStateLost {
+		select {
+		case l.suspended <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (l *LeaderLatch) workLoop() {
+	Log.Infoln("curator: start LeaderLatch.workLoop", l.latchPath)
+	defer func() {
+		Log.Infoln("curator: stop LeaderLatch.workLoop", l.latchPath)
+		l.wg.Done()
+	}()
+
+	for {
+		eventChan, err := l.checkLeadership()
+		if err != nil {
+			if l.ctx.Err() != nil {
+				return
+			}
+			Log.Errorln("curator: LeaderLatch failed to check leadership, path:", l.latchPath, "err:", err)
+			select {
+			case <-l.quit:
+				return
+			case <-time.After(cacheRetryInterval):
+			}
+			continue
+		}
+
+		select {
+		case <-l.quit:
+			return
+		case <-eventChan:
+		case <-l.suspended:
+			l.setLeadership(false)
+		}
+	}
+}
+
+func (l *LeaderLatch) checkLeadership() (<-chan zk.Event, error) {
+	l.mutex.Lock()
+	ourPath := l.ourPath
+	l.mutex.Unlock()
+
+	if ourPath == "" {
+		var err error
+		ourPath, err = createTheLock(l.ctx, l.client, path.Join(l.latchPath, latchLockName), []byte(l.id), l.aclv)
+		if err != nil {
+			return nil, err
+		}
+		l.mutex.Lock()
+		l.ourPath = ourPath
+		l.mutex.Unlock()
+	}
+
+	children, _, err := l.client.ChildrenCtx(l.ctx, l.latchPath)
+	if err != nil {
+		return nil, err
+	}
+	children = sortLockChildren(children, latchLockName)
+
+	index := -1
+	for i, child := range children {
+		if child == path.Base(ourPath) {
+			index = i
+			break
+		}
+	}
+
+	watchPath := ourPath
+	switch {
+	case index < 0:
+		// our node is gone, maybe the session expired, so create it again
+		l.setLeadership(false)
+		l.mutex.Lock()
+		l.ourPath = ""
+		l.mutex.Unlock()
+		return l.checkLeadership()
+	case index == 0:
+		l.setLeadership(true)
+	default:
+		l.setLeadership(false)
+		watchPath = path.Join(l.latchPath, children[index-1])
+	}
+
+	exist, _, eventChan, err := l.client.ExistsWCtx(l.ctx, watchPath)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		// changed in the meantime, check it again
+		ch := make(chan zk.Event, 1)
+		ch <- zk.Event{}
+		return ch, nil
+	}
+	return eventChan, nil
+}
+
+func (l *LeaderLatch) setLeadership(leaderShip bool) {
+	l.mutex.Lock()
+	if l.leaderShip == leaderShip {
+		l.mutex.Unlock()
+		return
+	}
+	l.leaderShip = leaderShip
+	if leaderShip {
+		close(l.leaderCh)
+	} else {
+		l.leaderCh = make(chan struct{})
+	}
+	listeners := make([]LeaderLatchListener, 0, len(l.listeners))
+	for listener := range l.listeners {
+		listeners = append(listeners, listener)
+	}
+	l.mutex.Unlock()
+
+	Log.Infoln("curator: LeaderLatch path:", l.latchPath, "id:", l.id, "leadership:", leaderShip)
+	for _, listener := range listeners {
+		if leaderShip {
+			listener.IsLeader()
+		} else {
+			listener.NotLeader()
+		}
+	}
+}
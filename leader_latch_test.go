@@ -0,0 +1,109 @@
+package curator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+type mockLeaderLatchListener struct {
+	c chan bool
+}
+
+func (m *mockLeaderLatchListener) IsLeader() {
+	m.c <- true
+}
+
+func (m *mockLeaderLatchListener) NotLeader() {
+	m.c <- false
+}
+
+func TestLeaderLatch(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	latchPath := "/test/leader_latch_test"
+	defer DeleteAll(client, latchPath)
+
+	listener := &mockLeaderLatchListener{c: make(chan bool, 10)}
+	latch := NewLeaderLatch(client, latchPath, "first", zk.WorldACL(zk.PermAll))
+	latch.AddListener(listener)
+	if err := latch.Start(); err != nil {
+		t.Fatal("failed to latch.Start, err:", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := latch.Await(ctx); err != nil {
+		t.Fatal("failed to latch.Await, err:", err)
+	}
+	if !latch.HasLeadership() || !<-listener.c {
+		t.Fatal("unexpected leadership")
+	}
+
+	participant := NewLeaderLatch(client, latchPath, "second", zk.WorldACL(zk.PermAll))
+	if err := participant.Start(); err != nil {
+		t.Fatal("failed to participant.Start, err:", err)
+	}
+	defer participant.Close()
+
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer shortCancel()
+	if err := participant.Await(shortCtx); err != context.DeadlineExceeded {
+		t.Fatal("unexpected participant.Await result, err:", err)
+	}
+
+	participants, err := latch.GetParticipants()
+	if err != nil {
+		t.Fatal("failed to latch.GetParticipants, err:", err)
+	}
+	if len(participants) != 2 || participants[0] != (Participant{"first", true}) || participants[1] != (Participant{"second", false}) {
+		t.Fatal("unexpected participants:", participants)
+	}
+
+	latch.Close()
+	if <-listener.c {
+		t.Fatal("unexpected leadership after Close")
+	}
+	if err := participant.Await(ctx); err != nil {
+		t.Fatal("failed to participant.Await, err:", err)
+	}
+	leader, err := participant.GetLeader()
+	if err != nil || leader.ID != "second" {
+		t.Fatal("unexpected leader:", leader, "err:", err)
+	}
+}
+
+func TestLeaderLatch_CloseWithoutServer(t *testing.T) {
+	client, err := NewZookeeperClient(DefaultZookeeperFactory, NewFixedEnsembleProvider("127.0.0.1:2181"),
+		3*time.Second, 1*time.Second, NewRetryForever(100*time.Millisecond), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	latch := NewLeaderLatch(client, "/test/leader-latch-noserver", "a", zk.WorldACL(zk.PermAll))
+	if err := latch.Start(); err != nil {
+		t.Fatal("failed to latch.Start, err:", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	closed := make(chan struct{})
+	go func() {
+		latch.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close blocked while the ensemble is down")
+	}
+}
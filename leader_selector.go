@@ -1,40 +1,102 @@
 package curator
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
 )
 
+// LeaderSelectorListener may also implement ConnectionStateListener to be
+// told about suspended and reconnected connections.
 type LeaderSelectorListener interface {
 	TakeLeaderShip(client *ZookeeperClient, cancel <-chan struct{}) error
 }
 
 type LeaderSelector struct {
-	client     *ZookeeperClient
-	mutex      *Mutex
-	start      int32
-	leaderShip int32
-	listener   LeaderSelectorListener
-	done       chan struct{}
-	wg         *sync.WaitGroup
+	client      *ZookeeperClient
+	mutex       *Mutex
+	start       int32
+	leaderShip  int32
+	autoRequeue int32
+	listener    LeaderSelectorListener
+	errorPolicy ConnectionStateErrorPolicy
+	gracePeriod time.Duration
+	connState   int32
+	stateCh     chan ConnectionState
+	lock        sync.Mutex
+	interrupt   chan struct{}
+	requeue     chan struct{}
+	requeueable bool
+	ctx         context.Context
+	cancel      context.CancelFunc
+	done        chan struct{}
+	wg          *sync.WaitGroup
 }
 
+var _ ConnectionStateListener = &LeaderSelector{}
+
+// NewLeaderSelector keeps the leadership through a suspended connection until
+// the grace period, the session timeout by default, expires or the session is
+// lost, so a short network blip does not fail over.
 func NewLeaderSelector(client *ZookeeperClient, basePath string, listener LeaderSelectorListener, aclv []zk.ACL) *LeaderSelector {
 	return &LeaderSelector{
-		client:   client,
-		mutex:    NewMutex(client, basePath, aclv),
-		listener: listener,
-		wg:       new(sync.WaitGroup),
+		client:      client,
+		mutex:       NewMutex(client, basePath, aclv),
+		autoRequeue: 1,
+		listener:    listener,
+		errorPolicy: SessionConnectionStateErrorPolicy,
+		gracePeriod: client.sessionTimeout,
+		requeue:     make(chan struct{}, 1),
+		wg:          new(sync.WaitGroup),
 	}
 }
 
+// WithID sets the participant id stored in the lock node.
+func (l *LeaderSelector) WithID(id string) *LeaderSelector {
+	l.mutex.lockData = []byte(id)
+	return l
+}
+
+// AutoRequeue controls whether the selector joins the election again after
+// relinquishing the leadership, it is enabled by default.
+func (l *LeaderSelector) AutoRequeue(autoRequeue bool) *LeaderSelector {
+	if autoRequeue {
+		atomic.StoreInt32(&l.autoRequeue, 1)
+	} else {
+		atomic.StoreInt32(&l.autoRequeue, 0)
+	}
+	return l
+}
+
+// WithConnectionStateErrorPolicy sets which connection states revoke the
+// leadership, SessionConnectionStateErrorPolicy by default.
+// StandardConnectionStateErrorPolicy revokes it as soon as the connection is
+// suspended.
+func (l *LeaderSelector) WithConnectionStateErrorPolicy(policy ConnectionStateErrorPolicy) *LeaderSelector {
+	l.errorPolicy = policy
+	return l
+}
+
+// WithSuspendedGracePeriod sets how long the leadership survives a suspended
+// connection under SessionConnectionStateErrorPolicy, the session timeout
+// by default.
+func (l *LeaderSelector) WithSuspendedGracePeriod(gracePeriod time.Duration) *LeaderSelector {
+	l.gracePeriod = gracePeriod
+	return l
+}
+
 func (l *LeaderSelector) Start() error {
 	if !atomic.CompareAndSwapInt32(&l.start, 0, 1) {
 		return errors.New("curator: LeaderSelector already started")
 	}
+
+	l.stateCh = make(chan ConnectionState, 16)
+	l.client.GetConnectionStateListenable().AddListener(l)
+	l.ctx, l.cancel = context.WithCancel(context.Background())
 	l.done = make(chan struct{}, 1)
 	l.wg.Add(1)
 	go l.workLoop()
@@ -43,42 +105,123 @@ func (l *LeaderSelector) Start() error {
 
 func (l *LeaderSelector) Close() error {
 	if atomic.CompareAndSwapInt32(&l.start, 1, 0) {
+		l.client.GetConnectionStateListenable().RemoveListener(l)
+		l.cancel()
 		close(l.done)
 		l.wg.Wait()
 	}
 	return nil
 }
 
+// Requeue joins the election again when auto requeue is disabled. It fails
+// while the selector is still participating, that is until the relinquished
+// lock node is removed.
+func (l *LeaderSelector) Requeue() error {
+	if atomic.LoadInt32(&l.start) != 1 {
+		return errors.New("curator: LeaderSelector is not started")
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if !l.requeueable {
+		return errors.New("curator: LeaderSelector is already participating")
+	}
+	l.requeueable = false
+	l.requeue <- struct{}{}
+	return nil
+}
+
+// InterruptLeadership cancels the current TakeLeaderShip and relinquishes
+// the leadership.
+func (l *LeaderSelector) InterruptLeadership() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.interrupt != nil {
+		close(l.interrupt)
+		l.interrupt = nil
+	}
+}
+
 func (l *LeaderSelector) HasLeaderShip() bool {
 	return atomic.LoadInt32(&l.leaderShip) == 1
 }
 
-func (l *LeaderSelector) work() {
-	if err := l.mutex.Acquire(); err != nil {
-		return
+func (l *LeaderSelector) GetParticipants() ([]Participant, error) {
+	return getParticipants(l.client, l.mutex.basePath, l.mutex.lockName)
+}
+
+func (l *LeaderSelector) GetLeader() (Participant, error) {
+	return getLeader(l.client, l.mutex.basePath, l.mutex.lockName)
+}
+
+func (l *LeaderSelector) StateChanged(client *ZookeeperClient, state ConnectionState) {
+	if listener, ok := l.listener.(ConnectionStateListener); ok {
+		listener.StateChanged(client, state)
+	}
+	atomic.StoreInt32(&l.connState, int32(state))
+	select {
+	case l.stateCh <- state:
+	default:
+		Log.Warnln("curator: LeaderSelector dropped connection state:", state)
 	}
+}
 
-	atomic.StoreInt32(&l.leaderShip, 1)
+func (l *LeaderSelector) work() bool {
+	// states before joining the election do not matter, the ones delivered
+	// while acquiring are handled with the leadership
+	for drained := false; !drained; {
+		select {
+		case <-l.stateCh:
+		default:
+			drained = true
+		}
+	}
 
-	connectionCh := make(chan zk.Event, 1)
-	watcher := NewWatcher(func(ev zk.Event) {
-		if ev.State == zk.StateExpired || ev.State == zk.StateDisconnected {
+	if err := l.mutex.AcquireCtx(l.ctx); err != nil {
+		if l.ctx.Err() == nil {
+			Log.Errorln("curator: LeaderSelector failed to acquire mutex, err:", err)
 			select {
-			case connectionCh <- ev:
-			default:
+			case <-l.done:
+			case <-time.After(cacheRetryInterval):
 			}
 		}
-	})
-	l.client.AddWatcher(watcher)
+		return false
+	}
+
+	state := ConnectionState(atomic.LoadInt32(&l.connState))
+	if l.errorPolicy.IsErrorState(state) {
+		Log.Warnln("curator: LeaderSelector relinquish leadership, connection state:", state)
+		l.mutex.Release()
+		return true
+	}
+
+	atomic.StoreInt32(&l.leaderShip, 1)
+
+	interrupt := make(chan struct{})
+	l.lock.Lock()
+	l.interrupt = interrupt
+	l.lock.Unlock()
 
 	cancel := make(chan struct{})
 	var wg sync.WaitGroup
+	var graceTimer *time.Timer
+	if state == ConnectionStateSuspended {
+		graceTimer = time.NewTimer(l.gracePeriod)
+	}
 	defer func() {
+		l.lock.Lock()
+		l.interrupt = nil
+		l.lock.Unlock()
+
+		if graceTimer != nil {
+			graceTimer.Stop()
+		}
 		atomic.StoreInt32(&l.leaderShip, 0)
-		l.mutex.Release()
-		l.client.DelWatcher(watcher)
+		// TakeLeaderShip has to stop before the release, which may block in
+		// the retry loop while the connection is suspended
 		close(cancel)
 		wg.Wait()
+		l.mutex.Release()
 	}()
 
 	runErrCh := make(chan error, 1)
@@ -88,16 +231,50 @@ func (l *LeaderSelector) work() {
 		runErrCh <- l.listener.TakeLeaderShip(l.client, cancel)
 	}()
 
-	select {
-	case <-runErrCh:
-	case <-connectionCh:
-	case <-l.done:
+	for {
+		var graceCh <-chan time.Time
+		if graceTimer != nil {
+			graceCh = graceTimer.C
+		}
+
+		select {
+		case <-runErrCh:
+			return true
+		case state := <-l.stateCh:
+			if l.errorPolicy.IsErrorState(state) {
+				Log.Warnln("curator: LeaderSelector relinquish leadership, connection state:", state)
+				return true
+			}
+			if state == ConnectionStateSuspended && graceTimer == nil {
+				graceTimer = time.NewTimer(l.gracePeriod)
+			} else if state.IsConnected() && graceTimer != nil {
+				graceTimer.Stop()
+				graceTimer = nil
+			}
+		case <-graceCh:
+			Log.Warnln("curator: LeaderSelector relinquish leadership, suspended longer than", l.gracePeriod)
+			return true
+		case <-interrupt:
+			return true
+		case <-l.done:
+			return true
+		}
 	}
 }
 
 func (l *LeaderSelector) workLoop() {
 	defer l.wg.Done()
 	for atomic.LoadInt32(&l.start) == 1 {
-		l.work()
+		if l.work() && atomic.LoadInt32(&l.autoRequeue) == 0 {
+			l.lock.Lock()
+			l.requeueable = true
+			l.lock.Unlock()
+
+			select {
+			case <-l.done:
+				return
+			case <-l.requeue:
+			}
+		}
 	}
 }
@@ -43,3 +43,156 @@ func TestLeaderSelector(t *testing.T) {
 		t.Fatal("leader failed")
 	}
 }
+
+type blockingLeaderSelectorListener struct {
+	c chan struct{}
+}
+
+func (b *blockingLeaderSelectorListener) TakeLeaderShip(client *ZookeeperClient, cancel <-chan struct{}) error {
+	b.c <- struct{}{}
+	<-cancel
+	return nil
+}
+
+func TestLeaderSelector_Participants(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	basePath := "/test/leader-participants-test"
+	firstListener := &blockingLeaderSelectorListener{c: make(chan struct{}, 10)}
+	first := NewLeaderSelector(client, basePath, firstListener, zk.WorldACL(zk.PermAll)).WithID("first").AutoRequeue(false)
+	first.Start()
+	defer first.Close()
+
+	select {
+	case <-firstListener.c:
+	case <-time.After(1 * time.Second):
+		t.Fatal("leader failed")
+	}
+
+	secondListener := &blockingLeaderSelectorListener{c: make(chan struct{}, 10)}
+	second := NewLeaderSelector(client, basePath, secondListener, zk.WorldACL(zk.PermAll)).WithID("second")
+	second.Start()
+	defer second.Close()
+
+	var participants []Participant
+	for i := 0; i < 10 && len(participants) < 2; i++ {
+		time.Sleep(50 * time.Millisecond)
+		if participants, err = first.GetParticipants(); err != nil {
+			t.Fatal("failed to GetParticipants, err:", err)
+		}
+	}
+	if len(participants) != 2 || participants[0] != (Participant{"first", true}) || participants[1] != (Participant{"second", false}) {
+		t.Fatal("unexpected participants:", participants)
+	}
+
+	first.InterruptLeadership()
+	select {
+	case <-secondListener.c:
+	case <-time.After(1 * time.Second):
+		t.Fatal("second failed to take leadership")
+	}
+	if leader, err := second.GetLeader(); err != nil || leader.ID != "second" {
+		t.Fatal("unexpected leader:", leader, "err:", err)
+	}
+	if first.HasLeaderShip() {
+		t.Fatal("unexpected leadership of interrupted selector")
+	}
+
+	// the interrupted selector may still be removing its lock node
+	for i := 0; ; i++ {
+		if err = first.Requeue(); err == nil {
+			break
+		} else if i == 20 {
+			t.Fatal("failed to Requeue, err:", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err := first.Requeue(); err == nil {
+		t.Fatal("Requeue must fail while participating")
+	}
+	second.InterruptLeadership()
+	select {
+	case <-firstListener.c:
+	case <-time.After(1 * time.Second):
+		t.Fatal("requeued selector failed to take leadership")
+	}
+}
+
+func TestLeaderSelector_SuspendedWhileAcquiring(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// let the initial connected state be delivered
+	if _, _, err := client.Exists("/"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	listener := &blockingLeaderSelectorListener{c: make(chan struct{}, 10)}
+	selector := NewLeaderSelector(client, "/test/leader-suspended-test", listener, zk.WorldACL(zk.PermAll)).
+		WithConnectionStateErrorPolicy(StandardConnectionStateErrorPolicy).
+		AutoRequeue(false)
+	// the connection is suspended by the time the lock is acquired
+	selector.StateChanged(client, ConnectionStateSuspended)
+	selector.Start()
+	defer selector.Close()
+
+	select {
+	case <-listener.c:
+		t.Fatal("unexpected leadership while suspended")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	selector.StateChanged(client, ConnectionStateReconnected)
+	if err := selector.Requeue(); err != nil {
+		t.Fatal("failed to Requeue, err:", err)
+	}
+	select {
+	case <-listener.c:
+	case <-time.After(1 * time.Second):
+		t.Fatal("leader failed after reconnecting")
+	}
+}
+
+func TestLeaderSelector_SuspendedGracePeriod(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	listener := &blockingLeaderSelectorListener{c: make(chan struct{}, 10)}
+	selector := NewLeaderSelector(client, "/test/leader-grace-test", listener, zk.WorldACL(zk.PermAll)).
+		WithSuspendedGracePeriod(300 * time.Millisecond).
+		AutoRequeue(false)
+	selector.Start()
+	defer selector.Close()
+
+	select {
+	case <-listener.c:
+	case <-time.After(1 * time.Second):
+		t.Fatal("leader failed")
+	}
+
+	// a short suspension keeps the leadership by default
+	selector.StateChanged(client, ConnectionStateSuspended)
+	time.Sleep(100 * time.Millisecond)
+	selector.StateChanged(client, ConnectionStateReconnected)
+	time.Sleep(400 * time.Millisecond)
+	if !selector.HasLeaderShip() {
+		t.Fatal("leadership must survive a short suspension")
+	}
+
+	selector.StateChanged(client, ConnectionStateSuspended)
+	time.Sleep(500 * time.Millisecond)
+	if selector.HasLeaderShip() {
+		t.Fatal("leadership must be revoked after the grace period")
+	}
+}
@@ -0,0 +1,82 @@
+package curator
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// LockKeyer may be implemented by a Locker to be ordered within MultiLock,
+// lockers guarding the same resource must return the same key.
+type LockKeyer interface {
+	LockKey() string
+}
+
+// MultiLock acquires all lockers in a fixed order, so two MultiLocks over the
+// same lockers cannot deadlock whatever order they were given in. Lockers
+// implementing LockKeyer, like Mutex, are sorted by key, the others follow in
+// slice order. If any of them fails the ones already acquired are released in
+// reverse order.
+type MultiLock struct {
+	lockers []Locker
+}
+
+var _ ContextLocker = &MultiLock{}
+
+func NewMultiLock(lockers []Locker) *MultiLock {
+	sorted := append([]Locker(nil), lockers...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		lhs, lok := sorted[i].(LockKeyer)
+		rhs, rok := sorted[j].(LockKeyer)
+		if lok && rok {
+			return lhs.LockKey() < rhs.LockKey()
+		}
+		return lok && !rok
+	})
+	return &MultiLock{lockers: sorted}
+}
+
+func (m *MultiLock) Acquire() error {
+	return m.AcquireCtx(context.Background())
+}
+
+func (m *MultiLock) AcquireTimeout(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return m.AcquireCtx(ctx)
+}
+
+// AcquireCtx gives up when ctx is done, lockers which are not ContextLocker
+// are only checked against ctx before acquiring.
+func (m *MultiLock) AcquireCtx(ctx context.Context) error {
+	for i, locker := range m.lockers {
+		var err error
+		if err = ctx.Err(); err == nil {
+			if l, ok := locker.(ContextLocker); ok {
+				err = l.AcquireCtx(ctx)
+			} else {
+				err = locker.Acquire()
+			}
+		}
+		if err != nil {
+			m.release(m.lockers[:i])
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MultiLock) Release() error {
+	return m.release(m.lockers)
+}
+
+func (m *MultiLock) release(lockers []Locker) error {
+	var lastErr error
+	for i := len(lockers) - 1; i >= 0; i-- {
+		if err := lockers[i].Release(); err != nil {
+			Log.Warnln("curator: MultiLock failed to release locker, err:", err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
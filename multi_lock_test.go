@@ -0,0 +1,82 @@
+package curator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestMultiLock(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	first := NewMutex(client, "/test/multi_lock_test/a", zk.WorldACL(zk.PermAll))
+	second := NewMutex(client, "/test/multi_lock_test/b", zk.WorldACL(zk.PermAll))
+	lock := NewMultiLock([]Locker{first, second})
+	if err := lock.Acquire(); err != nil {
+		t.Fatal("failed to lock.Acquire, err:", err)
+	}
+	if !first.IsAcquiredInThisProcess() || !second.IsAcquiredInThisProcess() {
+		t.Fatal("all lockers must be acquired")
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal("failed to lock.Release, err:", err)
+	}
+
+	holder := NewMutex(client, "/test/multi_lock_test/b", zk.WorldACL(zk.PermAll))
+	if err := holder.Acquire(); err != nil {
+		t.Fatal("failed to holder.Acquire, err:", err)
+	}
+	defer holder.Release()
+
+	if err := lock.AcquireTimeout(100 * time.Millisecond); err == nil {
+		t.Fatal("unexpected lock.AcquireTimeout result")
+	}
+	if first.IsAcquiredInThisProcess() || second.IsAcquiredInThisProcess() {
+		t.Fatal("acquired lockers must be released on failure")
+	}
+}
+
+func TestMultiLock_OppositeOrders(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	newLock := func(basePaths ...string) *MultiLock {
+		var lockers []Locker
+		for _, basePath := range basePaths {
+			lockers = append(lockers, NewMutex(client, basePath, zk.WorldACL(zk.PermAll)))
+		}
+		return NewMultiLock(lockers)
+	}
+	a, b := "/test/multi_lock_order_test/a", "/test/multi_lock_order_test/b"
+	locks := []*MultiLock{newLock(a, b), newLock(b, a)}
+	if locks[0].lockers[0].(LockKeyer).LockKey() != locks[1].lockers[0].(LockKeyer).LockKey() {
+		t.Fatal("lockers must be acquired in the same order")
+	}
+
+	errs := make(chan error, len(locks))
+	for _, lock := range locks {
+		go func(lock *MultiLock) {
+			for i := 0; i < 10; i++ {
+				if err := lock.AcquireTimeout(3 * time.Second); err != nil {
+					errs <- err
+					return
+				}
+				lock.Release()
+			}
+			errs <- nil
+		}(lock)
+	}
+	for range locks {
+		if err := <-errs; err != nil {
+			t.Fatal("failed to acquire locks given in opposite orders, err:", err)
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package curator
 
 import (
+	"context"
+	"math"
 	"path"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
 )
@@ -13,31 +18,65 @@ type Locker interface {
 	Release() error
 }
 
+type ContextLocker interface {
+	Locker
+	AcquireCtx(ctx context.Context) error
+}
+
+// Mutex is reentrant, the owner of the lock is the Mutex itself. Nested
+// Acquire and Release pairs on the same Mutex only count, use one Mutex
+// per participant.
 type Mutex struct {
-	client   *ZookeeperClient
-	basePath string
-	lockName string
-	lockPath string
-	aclv     []zk.ACL
+	client      *ZookeeperClient
+	basePath    string
+	lockName    string
+	sortName    string
+	getsTheLock lockPredicate
+	lockData    []byte
+	aclv        []zk.ACL
+	mutex       sync.Mutex
+	lockPath    string
+	lockCount   int
 }
 
-var _ Locker = &Mutex{}
+var (
+	_ ContextLocker = &Mutex{}
+	_ LockKeyer     = &Mutex{}
+)
+
+// lockPredicate reports whether the node at index of the sorted children
+// holds the lock, otherwise it returns the node to wait for.
+type lockPredicate func(children []string, index int) (acquired bool, watchNode string)
 
 func NewMutex(client *ZookeeperClient, basePath string, aclv []zk.ACL) *Mutex {
+	return newMutex(client, basePath, "lock-", "lock-", standardLockPredicate, aclv)
+}
+
+func newMutex(client *ZookeeperClient, basePath, lockName, sortName string, getsTheLock lockPredicate, aclv []zk.ACL) *Mutex {
 	return &Mutex{
-		client:   client,
-		basePath: basePath,
-		lockName: "lock-",
-		aclv:     aclv,
+		client:      client,
+		basePath:    basePath,
+		lockName:    lockName,
+		sortName:    sortName,
+		getsTheLock: getsTheLock,
+		lockData:    []byte{},
+		aclv:        aclv,
 	}
 }
 
-func createTheLock(client *ZookeeperClient, lockPath string, data []byte, aclv []zk.ACL) (string, error) {
+func standardLockPredicate(children []string, index int) (bool, string) {
+	if index < 1 {
+		return true, ""
+	}
+	return false, children[index-1]
+}
+
+func createTheLock(ctx context.Context, client *ZookeeperClient, lockPath string, data []byte, aclv []zk.ACL) (string, error) {
 	var nodePath string
 	var err error
 
 	for i := 0; i < 2; i++ {
-		nodePath, err = client.CreateProtectedEphemeralSequential(lockPath, data, aclv)
+		nodePath, err = client.CreateProtectedEphemeralSequentialCtx(ctx, lockPath, data, aclv)
 		if err == nil {
 			break
 		} else if err == zk.ErrNoNode {
@@ -54,15 +93,36 @@ func createTheLock(client *ZookeeperClient, lockPath string, data []byte, aclv [
 }
 
 func (m *Mutex) Acquire() error {
+	return m.AcquireCtx(context.Background())
+}
+
+func (m *Mutex) AcquireTimeout(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return m.AcquireCtx(ctx)
+}
+
+func (m *Mutex) AcquireCtx(ctx context.Context) error {
+	m.mutex.Lock()
+	if m.lockCount > 0 {
+		m.lockCount++
+		m.mutex.Unlock()
+		return nil
+	}
+	m.mutex.Unlock()
+
 	for {
-		nodePath, err := createTheLock(m.client, path.Join(m.basePath, m.lockName), []byte{}, m.aclv)
+		nodePath, err := createTheLock(ctx, m.client, path.Join(m.basePath, m.lockName), m.lockData, m.aclv)
 		if err != nil {
 			return err
 		}
 
-		err = m.internalLockLoop(nodePath)
+		err = m.internalLockLoop(ctx, nodePath)
 		if err == nil {
+			m.mutex.Lock()
 			m.lockPath = nodePath
+			m.lockCount = 1
+			m.mutex.Unlock()
 			break
 
 		} else if err != zk.ErrNoNode {
@@ -83,36 +143,85 @@ func (m *mutexSortChildren) Len() int {
 }
 
 func (m *mutexSortChildren) Less(i, j int) bool {
-	lhs := strings.Split(m.children[i], m.lockName)
-	rhs := strings.Split(m.children[j], m.lockName)
-	return lhs[len(lhs)-1] < rhs[len(rhs)-1]
+	lhs := lockSequence(m.children[i], m.lockName)
+	rhs := lockSequence(m.children[j], m.lockName)
+	if lhs != rhs {
+		return lhs < rhs
+	}
+	return m.children[i] < m.children[j]
 }
 
 func (m *mutexSortChildren) Swap(i, j int) {
 	m.children[i], m.children[j] = m.children[j], m.children[i]
 }
 
-func (m *Mutex) internalLockLoop(nodePath string) error {
+// lockSequence returns the sequence number following the last lockName, so the
+// protected prefix "_c_<guid>-" is ignored. Unparsable names sort last.
+func lockSequence(child, lockName string) int64 {
+	index := strings.LastIndex(child, lockName)
+	if index < 0 {
+		return math.MaxInt64
+	}
+	seq, err := strconv.ParseInt(child[index+len(lockName):], 10, 64)
+	if err != nil {
+		return math.MaxInt64
+	}
+	return seq
+}
+
+func sortLockChildren(children []string, lockName string) []string {
+	filtered := make([]string, 0, len(children))
+	for _, child := range children {
+		if strings.Contains(child, lockName) {
+			filtered = append(filtered, child)
+		}
+	}
+	sort.Sort(&mutexSortChildren{lockName, filtered})
+	return filtered
+}
+
+func (m *Mutex) GetParticipantNodes() ([]string, error) {
+	children, _, err := m.client.Children(m.basePath)
+	if err != nil {
+		if err == zk.ErrNoNode {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	children = sortLockChildren(children, m.sortName)
+	for i, child := range children {
+		children[i] = path.Join(m.basePath, child)
+	}
+	return children, nil
+}
+
+func (m *Mutex) LockKey() string {
+	return path.Join(m.basePath, m.lockName)
+}
+
+func (m *Mutex) IsAcquiredInThisProcess() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.lockCount > 0
+}
+
+func (m *Mutex) internalLockLoop(ctx context.Context, nodePath string) error {
 	deleteNode := true
 	defer func() {
 		if deleteNode {
-			m.client.Delete(path.Join(m.basePath, m.lockPath), -1)
+			m.client.Delete(nodePath, -1)
 		}
 	}()
 
 	seq := path.Base(nodePath)
 
-	sorter := &mutexSortChildren{}
-
 	for {
-		children, _, err := m.client.Children(m.basePath)
+		children, _, err := m.client.ChildrenCtx(ctx, m.basePath)
 		if err != nil {
 			return err
 		}
-
-		sorter.lockName = m.lockName
-		sorter.children = children
-		sort.Sort(sorter)
+		children = sortLockChildren(children, m.sortName)
 
 		index := -1
 		for i, v := range children {
@@ -127,13 +236,12 @@ func (m *Mutex) internalLockLoop(nodePath string) error {
 			return zk.ErrNoNode
 		}
 
-		if index < 1 {
+		if acquired, watchNode := m.getsTheLock(children, index); acquired {
 			// got the lock
 			deleteNode = false
 			break
 		} else {
-			previousSeq := children[index-1]
-			exist, _, ch, err := m.client.ExistsW(path.Join(m.basePath, previousSeq))
+			exist, _, ch, err := m.client.ExistsWCtx(ctx, path.Join(m.basePath, watchNode))
 			if err != nil {
 				return err
 			}
@@ -142,9 +250,13 @@ func (m *Mutex) internalLockLoop(nodePath string) error {
 				continue
 			}
 
-			ev := <-ch
-			if ev.Err != nil {
-				return ev.Err
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case ev := <-ch:
+				if ev.Err != nil {
+					return ev.Err
+				}
 			}
 		}
 	}
@@ -153,10 +265,18 @@ func (m *Mutex) internalLockLoop(nodePath string) error {
 }
 
 func (m *Mutex) Release() error {
-	if m.lockPath == "" {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.lockCount == 0 {
 		return zk.ErrNotLocked
 	}
 
+	m.lockCount--
+	if m.lockCount > 0 {
+		return nil
+	}
+
 	err := m.client.Delete(m.lockPath, -1)
 	m.lockPath = ""
 	return err
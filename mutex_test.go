@@ -1,6 +1,8 @@
 package curator
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"sort"
@@ -93,3 +95,129 @@ func TestMutex_Release(t *testing.T) {
 		t.Fatal("failed to mutex.Release, err:", err)
 	}
 }
+
+func TestMutex_Reentrant(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	basePath := "/test/mutex_test"
+
+	mutex := NewMutex(client, basePath, zk.WorldACL(zk.PermAll))
+	if err := mutex.Acquire(); err != nil {
+		t.Fatal("failed to mutex.Acquire, err:", err)
+	}
+	if err := mutex.AcquireTimeout(100 * time.Millisecond); err != nil {
+		t.Fatal("failed to reenter mutex, err:", err)
+	}
+
+	participantMutex := NewMutex(client, basePath, zk.WorldACL(zk.PermAll))
+	if err := mutex.Release(); err != nil {
+		t.Fatal("failed to mutex.Release, err:", err)
+	}
+	if err := participantMutex.AcquireTimeout(100 * time.Millisecond); err == nil {
+		t.Fatal("participant must not acquire mutex held by nested owner")
+	}
+
+	if err := mutex.Release(); err != nil {
+		t.Fatal("failed to mutex.Release, err:", err)
+	}
+	if err := participantMutex.AcquireTimeout(1 * time.Second); err != nil {
+		t.Fatal("participant failed to acquire mutex, err:", err)
+	}
+	participantMutex.Release()
+}
+
+func TestMutex_AcquireTimeout(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	basePath := "/test/mutex_test"
+
+	mutex := NewMutex(client, basePath, zk.WorldACL(zk.PermAll))
+	if err := mutex.Acquire(); err != nil {
+		t.Fatal("failed to mutex.Acquire, err:", err)
+	}
+	defer mutex.Release()
+
+	participantMutex := NewMutex(client, basePath, zk.WorldACL(zk.PermAll))
+	if err := participantMutex.AcquireTimeout(100 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("unexpected err on participantMutex.AcquireTimeout, err:", err)
+	}
+
+	children, _, err := client.Children(basePath)
+	if err != nil {
+		t.Fatal("failed to client.Children, err:", err)
+	}
+	if len(children) != 1 {
+		t.Fatal("unexpected lock nodes after timeout:", children)
+	}
+}
+
+func TestMutexSortChildren_Protected(t *testing.T) {
+	children := []string{
+		"_c_ffff-lock-0000000003",
+		"_c_0000-lock-0000000001",
+		"other",
+		"lock-0000000002",
+	}
+	children = sortLockChildren(children, "lock-")
+	expected := []string{"_c_0000-lock-0000000001", "lock-0000000002", "_c_ffff-lock-0000000003"}
+	if len(children) != len(expected) {
+		t.Fatal("unexpected children:", children)
+	}
+	for i := range expected {
+		if children[i] != expected[i] {
+			t.Fatal("unexpected children:", children)
+		}
+	}
+}
+
+func TestMutex_GetParticipantNodes(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	basePath := "/test/mutex_test"
+
+	mutex := NewMutex(client, basePath, zk.WorldACL(zk.PermAll))
+	if mutex.IsAcquiredInThisProcess() {
+		t.Fatal("unexpected IsAcquiredInThisProcess before Acquire")
+	}
+	if err := mutex.Acquire(); err != nil {
+		t.Fatal("failed to mutex.Acquire, err:", err)
+	}
+	if !mutex.IsAcquiredInThisProcess() {
+		t.Fatal("unexpected IsAcquiredInThisProcess after Acquire")
+	}
+
+	participantMutex := NewMutex(client, basePath, zk.WorldACL(zk.PermAll))
+	ch := make(chan error, 1)
+	go func() {
+		ch <- participantMutex.Acquire()
+	}()
+
+	var nodes []string
+	for i := 0; i < 10 && len(nodes) < 2; i++ {
+		time.Sleep(50 * time.Millisecond)
+		if nodes, err = mutex.GetParticipantNodes(); err != nil {
+			t.Fatal("failed to mutex.GetParticipantNodes, err:", err)
+		}
+	}
+	if len(nodes) != 2 || nodes[0] != mutex.lockPath {
+		t.Fatal("unexpected participant nodes:", nodes, "lockPath:", mutex.lockPath)
+	}
+
+	mutex.Release()
+	if err := <-ch; err != nil {
+		t.Fatal("participant failed to acquire mutex, err:", err)
+	}
+	participantMutex.Release()
+}
@@ -0,0 +1,146 @@
+package curator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+type namespace struct {
+	prefix  string
+	ensured int32
+}
+
+func newNamespace(name string) *namespace {
+	name = strings.Trim(name, "/")
+	if name == "" {
+		return nil
+	}
+	return &namespace{prefix: "/" + name}
+}
+
+// UsingNamespace returns a view of the client whose operations are relative
+// to the namespace, an empty namespace returns a view without namespace. The
+// view shares the connection of the client, so Start and Close must be
+// called on the client itself. GetConn of the view is not namespaced.
+func (c *ZookeeperClient) UsingNamespace(name string) *ZookeeperClient {
+	root := c
+	if c.parent != nil {
+		root = c.parent
+	}
+
+	return &ZookeeperClient{
+		connectionState:   root.connectionState,
+		retryPolicy:       root.retryPolicy,
+		quit:              root.quit,
+		connectionTimeout: root.connectionTimeout,
+		parent:            root,
+		namespace:         newNamespace(name),
+	}
+}
+
+func (c *ZookeeperClient) GetNamespace() string {
+	if c.namespace == nil {
+		return ""
+	}
+	return strings.TrimPrefix(c.namespace.prefix, "/")
+}
+
+// fixPath prepends the namespace to nodePath, the path is not cleaned since
+// the trailing slash of a sequential node matters.
+func (c *ZookeeperClient) fixPath(nodePath string) (string, error) {
+	n := c.namespace
+	if n == nil {
+		return nodePath, nil
+	}
+
+	if !strings.HasPrefix(nodePath, "/") {
+		return "", zk.ErrInvalidPath
+	}
+	if nodePath == "/" {
+		return n.prefix, nil
+	}
+	return n.prefix + nodePath, nil
+}
+
+// ensureNamespace creates the namespace root before the first create. Other
+// operations do not need it, a missing root is reported as zk.ErrNoNode.
+func (c *ZookeeperClient) ensureNamespace(ctx context.Context) error {
+	n := c.namespace
+	if n == nil || atomic.LoadInt32(&n.ensured) == 1 {
+		return nil
+	}
+
+	if _, err := CreateAllCtx(ctx, c.parent, n.prefix, []byte{}, 0, zk.WorldACL(zk.PermAll)); err != nil && err != zk.ErrNodeExists {
+		return err
+	}
+	atomic.StoreInt32(&n.ensured, 1)
+	return nil
+}
+
+func (c *ZookeeperClient) unfixPath(nodePath string) string {
+	n := c.namespace
+	if n == nil {
+		return nodePath
+	}
+
+	if nodePath == n.prefix {
+		return "/"
+	}
+	return strings.TrimPrefix(nodePath, n.prefix)
+}
+
+func (c *ZookeeperClient) unfixWatch(watch <-chan zk.Event) <-chan zk.Event {
+	if c.namespace == nil || watch == nil {
+		return watch
+	}
+
+	ch := make(chan zk.Event, 1)
+	go func() {
+		defer close(ch)
+		if event, ok := <-watch; ok {
+			if event.Path != "" {
+				event.Path = c.unfixPath(event.Path)
+			}
+			ch <- event
+		}
+	}()
+	return ch
+}
+
+func (c *ZookeeperClient) fixOps(ops []interface{}) ([]interface{}, error) {
+	if c.namespace == nil {
+		return ops, nil
+	}
+
+	fixed := make([]interface{}, 0, len(ops))
+	for _, op := range ops {
+		var nodePath *string
+		switch req := op.(type) {
+		case *zk.CreateRequest:
+			r := *req
+			nodePath, op = &r.Path, &r
+		case *zk.SetDataRequest:
+			r := *req
+			nodePath, op = &r.Path, &r
+		case *zk.DeleteRequest:
+			r := *req
+			nodePath, op = &r.Path, &r
+		case *zk.CheckVersionRequest:
+			r := *req
+			nodePath, op = &r.Path, &r
+		default:
+			return nil, errors.New("curator: unknown multi operation")
+		}
+
+		var err error
+		if *nodePath, err = c.fixPath(*nodePath); err != nil {
+			return nil, err
+		}
+		fixed = append(fixed, op)
+	}
+	return fixed, nil
+}
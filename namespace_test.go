@@ -0,0 +1,109 @@
+package curator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestZookeeperClient_UsingNamespace(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal("failed to newZookeeperClient, err:", err)
+	}
+	defer client.Close()
+
+	DeleteAll(client, "/test-namespace")
+	defer DeleteAll(client, "/test-namespace")
+
+	view := client.UsingNamespace("test-namespace")
+	if view.GetNamespace() != "test-namespace" {
+		t.Fatal("unexpected namespace:", view.GetNamespace())
+	}
+	if err := view.Start(); err == nil {
+		t.Fatal("unexpected view.Start result")
+	}
+
+	exist, _, watch, err := view.ExistsW("/node")
+	if err != nil || exist {
+		t.Fatal("unexpected view.ExistsW result, exist:", exist, "err:", err)
+	}
+	if exist, _, _ := client.Exists("/test-namespace"); exist {
+		t.Fatal("namespace root must not be created by a read")
+	}
+
+	created, err := view.Create("/node", []byte("v"), 0, zk.WorldACL(zk.PermAll))
+	if err != nil || created != "/node" {
+		t.Fatal("unexpected view.Create result:", created, "err:", err)
+	}
+	if exist, _, _ := client.Exists("/test-namespace"); !exist {
+		t.Fatal("namespace root must be created on first create")
+	}
+	select {
+	case event := <-watch:
+		if event.Type != zk.EventNodeCreated || event.Path != "/node" {
+			t.Fatal("unexpected event:", event.Type, event.Path)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("deadline waiting for EventNodeCreated")
+	}
+
+	data, _, err := client.Get("/test-namespace/node")
+	if err != nil || string(data) != "v" {
+		t.Fatal("unexpected data:", string(data), "err:", err)
+	}
+
+	results, err := view.InTransaction().
+		Create("/txn", nil, 0, zk.WorldACL(zk.PermAll)).
+		Delete("/node", -1).
+		Commit()
+	if err != nil {
+		t.Fatal("failed to Commit, err:", err)
+	}
+	if results[0].ResultPath != "/txn" {
+		t.Fatal("unexpected result path:", results[0].ResultPath)
+	}
+
+	children, _, err := view.Children("/")
+	if err != nil || len(children) != 1 || children[0] != "txn" {
+		t.Fatal("unexpected children:", children, "err:", err)
+	}
+
+	if _, err := view.Create("relative", nil, 0, zk.WorldACL(zk.PermAll)); err != zk.ErrInvalidPath {
+		t.Fatal("unexpected result of a relative path:", err)
+	}
+
+	if _, err := view.Create("/queue", nil, 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to view.Create, err:", err)
+	}
+	created, err = view.Create("/queue/", nil, zk.FlagSequence, zk.WorldACL(zk.PermAll))
+	if err != nil || created != "/queue/0000000000" {
+		t.Fatal("unexpected sequential node:", created, "err:", err)
+	}
+}
+
+func TestZookeeperClient_UsingNamespaceWithoutRoot(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal("failed to newZookeeperClient, err:", err)
+	}
+	defer client.Close()
+
+	DeleteAll(client, "/test-namespace-absent")
+	view := client.UsingNamespace("test-namespace-absent")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := view.CreateCtx(ctx, "/node", nil, 0, zk.WorldACL(zk.PermAll)); !errors.Is(err, context.Canceled) {
+		t.Fatal("unexpected view.CreateCtx result:", err)
+	}
+
+	client.processEvent(zk.Event{Type: zk.EventSession, State: zk.StateConnectedReadOnly})
+	defer client.processEvent(zk.Event{Type: zk.EventSession, State: zk.StateHasSession})
+	if _, _, err := view.Get("/node"); err != zk.ErrNoNode {
+		t.Fatal("unexpected view.Get result in a read-only session:", err)
+	}
+}
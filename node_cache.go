@@ -0,0 +1,180 @@
+package curator
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+const cacheRetryInterval = 1 * time.Second
+
+type NodeCacheEvent struct {
+	Node string
+	Data []byte
+	Stat *zk.Stat
+	Type NodeCacheEventType
+}
+
+type NodeCacheEventType int
+
+const (
+	NodeCacheCreate NodeCacheEventType = 1
+	NodeCacheUpdate NodeCacheEventType = 2
+	NodeCacheDelete NodeCacheEventType = 3
+)
+
+func (n NodeCacheEventType) String() string {
+	switch n {
+	case NodeCacheCreate:
+		return "NodeCacheCreate"
+	case NodeCacheUpdate:
+		return "NodeCacheUpdate"
+	case NodeCacheDelete:
+		return "NodeCacheDelete"
+	}
+	return "unknown"
+}
+
+type OnNodeCacheChange func(event NodeCacheEvent)
+
+type NodeCache struct {
+	start    int32
+	client   *ZookeeperClient
+	node     string
+	callback OnNodeCacheChange
+	mutex    sync.RWMutex
+	data     []byte
+	stat     *zk.Stat
+	quit     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewNodeCache(client *ZookeeperClient, node string, callback OnNodeCacheChange) *NodeCache {
+	return &NodeCache{
+		client:   client,
+		node:     node,
+		callback: callback,
+	}
+}
+
+func (n *NodeCache) Start() error {
+	if !atomic.CompareAndSwapInt32(&n.start, 0, 1) {
+		return errors.New("curator: NodeCache already started")
+	}
+
+	eventChan, err := n.refresh()
+	if err != nil {
+		atomic.StoreInt32(&n.start, 0)
+		return err
+	}
+
+	n.quit = make(chan struct{}, 1)
+	n.wg.Add(1)
+	go n.watchNode(eventChan)
+	return nil
+}
+
+func (n *NodeCache) Close() error {
+	if !atomic.CompareAndSwapInt32(&n.start, 1, 0) {
+		return errors.New("curator: NodeCache already closed")
+	}
+
+	close(n.quit)
+	n.wg.Wait()
+	return nil
+}
+
+func (n *NodeCache) GetCurrentData() (data []byte, stat *zk.Stat, ok bool) {
+	n.mutex.RLock()
+	data, stat = n.data, n.stat
+	n.mutex.RUnlock()
+	return data, stat, stat != nil
+}
+
+func (n *NodeCache) watchNode(eventChan <-chan zk.Event) {
+	Log.Infoln("curator: start NodeCache.watchNode", n.node)
+	defer func() {
+		Log.Infoln("curator: stop NodeCache.watchNode", n.node)
+		n.wg.Done()
+	}()
+
+	for {
+		select {
+		case <-n.quit:
+			return
+		case event, ok := <-eventChan:
+			// the watch is gone after any event, including EventNotWatching on
+			// session expiry, so resubscribe in every case.
+			if ok && event.Type == zk.EventNotWatching {
+				Log.Warnln("curator: NodeCache lost watch, node:", n.node, "err:", event.Err)
+			}
+		}
+
+		for {
+			var err error
+			eventChan, err = n.refresh()
+			if err == nil {
+				break
+			}
+			Log.Errorln("curator: NodeCache failed to refresh, node:", n.node, "err:", err)
+
+			select {
+			case <-n.quit:
+				return
+			case <-time.After(cacheRetryInterval):
+			}
+		}
+	}
+}
+
+func (n *NodeCache) refresh() (<-chan zk.Event, error) {
+	exist, _, existChan, err := n.client.ExistsW(n.node)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		n.update(nil, nil)
+		return existChan, nil
+	}
+
+	data, stat, dataChan, err := n.client.GetW(n.node)
+	if err != nil {
+		if err != zk.ErrNoNode {
+			return nil, err
+		}
+		// deleted between ExistsW and GetW, existChan will report it
+		n.update(nil, nil)
+		return existChan, nil
+	}
+
+	n.update(data, stat)
+	return dataChan, nil
+}
+
+func (n *NodeCache) update(data []byte, stat *zk.Stat) {
+	n.mutex.Lock()
+	oldStat := n.stat
+	n.data, n.stat = data, stat
+	n.mutex.Unlock()
+
+	switch {
+	case oldStat == nil && stat == nil:
+	case oldStat == nil:
+		n.notify(NodeCacheEvent{Node: n.node, Data: data, Stat: stat, Type: NodeCacheCreate})
+	case stat == nil:
+		n.notify(NodeCacheEvent{Node: n.node, Type: NodeCacheDelete})
+	case oldStat.Mzxid != stat.Mzxid || oldStat.Czxid != stat.Czxid:
+		n.notify(NodeCacheEvent{Node: n.node, Data: data, Stat: stat, Type: NodeCacheUpdate})
+	}
+}
+
+func (n *NodeCache) notify(event NodeCacheEvent) {
+	Log.Infoln("curator: NodeCache node:", event.Node, "event:", event.Type)
+
+	if n.callback != nil {
+		n.callback(event)
+	}
+}
@@ -0,0 +1,72 @@
+package curator
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+const nodeCacheNode = "/test/nodeCache"
+
+func TestNodeCache(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal("failed to newZookeeperClient, err:", err)
+	}
+	defer client.Close()
+	DeleteAll(client, nodeCacheNode)
+
+	c := make(chan NodeCacheEvent, 3)
+	cache := NewNodeCache(client, nodeCacheNode, func(event NodeCacheEvent) {
+		c <- event
+	})
+	if err := cache.Start(); err != nil {
+		t.Fatal("failed to cache.Start, err:", err)
+	}
+	defer cache.Close()
+
+	if _, _, ok := cache.GetCurrentData(); ok {
+		t.Fatal("unexpected data for absent node")
+	}
+
+	expectEvent := func(expectedType NodeCacheEventType, expectedData []byte) {
+		select {
+		case event := <-c:
+			if event.Type != expectedType {
+				t.Fatal("unexpected event type, expected:", expectedType, "but:", event.Type)
+			}
+			if !bytes.Equal(event.Data, expectedData) {
+				t.Fatal("unexpected event data:", string(event.Data))
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatal("deadline waiting for", expectedType)
+		}
+	}
+
+	if _, err := CreateAll(client, nodeCacheNode, []byte("v1"), 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to CreateAll, err:", err)
+	}
+	defer DeleteAll(client, nodeCacheNode)
+	expectEvent(NodeCacheCreate, []byte("v1"))
+
+	if _, err := client.Set(nodeCacheNode, []byte("v2"), -1); err != nil {
+		t.Fatal("failed to client.Set, err:", err)
+	}
+	expectEvent(NodeCacheUpdate, []byte("v2"))
+
+	data, stat, ok := cache.GetCurrentData()
+	if !ok || stat == nil || !bytes.Equal(data, []byte("v2")) {
+		t.Fatal("unexpected current data:", string(data))
+	}
+
+	if err := client.Delete(nodeCacheNode, -1); err != nil {
+		t.Fatal("failed to client.Delete, err:", err)
+	}
+	expectEvent(NodeCacheDelete, nil)
+
+	if _, _, ok := cache.GetCurrentData(); ok {
+		t.Fatal("unexpected data for deleted node")
+	}
+}
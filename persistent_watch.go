@@ -0,0 +1,215 @@
+package curator
+
+import (
+	"errors"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+type PersistentWatchHandler func(event zk.Event)
+
+// PersistentWatch keeps delivering the events of a path, and of all its
+// descendants when recursive, until it is removed. go-zookeeper has no
+// addWatch, so it is always emulated by re-arming one-shot watches: changes
+// made while a watch is re-armed are coalesced, e.g. a data change right after
+// another is missed, and a node created and deleted in between is not seen.
+type PersistentWatch struct {
+	start     int32
+	client    *ZookeeperClient
+	path      string
+	recursive bool
+	handler   PersistentWatchHandler
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+type persistentWatchEvent struct {
+	path     string
+	event    zk.Event
+	children bool
+}
+
+func (c *ZookeeperClient) AddPersistentWatch(path string, recursive bool, handler PersistentWatchHandler) (*PersistentWatch, error) {
+	if handler == nil {
+		return nil, errors.New("curator: handler cannot be nil")
+	}
+
+	w := &PersistentWatch{
+		start:     1,
+		client:    c,
+		path:      path,
+		recursive: recursive,
+		handler:   handler,
+		quit:      make(chan struct{}),
+	}
+	w.wg.Add(1)
+	go w.watchLoop()
+	return w, nil
+}
+
+func (w *PersistentWatch) Remove() error {
+	if !atomic.CompareAndSwapInt32(&w.start, 1, 0) {
+		return errors.New("curator: PersistentWatch already removed")
+	}
+
+	close(w.quit)
+	w.wg.Wait()
+	return nil
+}
+
+func (w *PersistentWatch) watchLoop() {
+	Log.Infoln("curator: start PersistentWatch.watchLoop", w.path)
+	defer func() {
+		Log.Infoln("curator: stop PersistentWatch.watchLoop", w.path)
+		w.wg.Done()
+	}()
+
+	for {
+		err := w.watchEmulated()
+		if err == nil {
+			return
+		}
+
+		Log.Errorln("curator: PersistentWatch failed to watch, path:", w.path, "err:", err)
+		select {
+		case <-w.quit:
+			return
+		case <-time.After(cacheRetryInterval):
+		}
+	}
+}
+
+// watchEmulated returns nil when the watch is removed, otherwise all the
+// watches have to be armed again.
+func (w *PersistentWatch) watchEmulated() error {
+	events := make(chan persistentWatchEvent, 64)
+	done := make(chan struct{})
+	defer close(done)
+
+	forward := func(nodePath string, eventChan <-chan zk.Event, children bool) {
+		go func() {
+			select {
+			case <-done:
+			case event := <-eventChan:
+				select {
+				case <-done:
+				case events <- persistentWatchEvent{nodePath, event, children}:
+				}
+			}
+		}()
+	}
+
+	// nodes holds the watched paths and which of their one-shot watches are armed
+	nodes := map[string]*persistentWatchNode{w.path: {}}
+	armExists := func(nodePath string) error {
+		node := nodes[nodePath]
+		if node.exists {
+			return nil
+		}
+		_, _, eventChan, err := w.client.ExistsW(nodePath)
+		if err != nil {
+			return err
+		}
+		forward(nodePath, eventChan, false)
+		node.exists = true
+		return nil
+	}
+
+	var arm func(nodePath string, notify bool) error
+	arm = func(nodePath string, notify bool) error {
+		if err := armExists(nodePath); err != nil {
+			return err
+		}
+		node := nodes[nodePath]
+		if node.children || (!w.recursive && nodePath != w.path) {
+			return nil
+		}
+
+		children, _, eventChan, err := w.client.ChildrenW(nodePath)
+		if err == zk.ErrNoNode {
+			// the exists watch reports the creation
+			return nil
+		} else if err != nil {
+			return err
+		}
+		forward(nodePath, eventChan, true)
+		node.children = true
+
+		if !w.recursive {
+			return nil
+		}
+		for _, child := range children {
+			childPath := path.Join(nodePath, child)
+			if nodes[childPath] != nil {
+				continue
+			}
+			nodes[childPath] = &persistentWatchNode{}
+			// armed before the handler runs, so a change right after is seen
+			if err := armExists(childPath); err != nil {
+				return err
+			}
+			if notify {
+				w.handler(zk.Event{Type: zk.EventNodeCreated, State: zk.StateHasSession, Path: childPath})
+			}
+			if err := arm(childPath, notify); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := arm(w.path, false); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-w.quit:
+			return nil
+		case e := <-events:
+			if e.event.Type == zk.EventNotWatching {
+				// the session expired, so all watches have to be armed again
+				return e.event.Err
+			}
+
+			node := nodes[e.path]
+			if node == nil {
+				continue
+			}
+			if e.children {
+				node.children = false
+			} else {
+				node.exists = false
+			}
+
+			// deletion is reported by both watches, only the exists watch is delivered
+			if !e.children || (!w.recursive && e.event.Type == zk.EventNodeChildrenChanged) {
+				w.handler(e.event)
+			}
+			if e.event.Type == zk.EventNodeDeleted && e.path != w.path {
+				if !e.children {
+					for p := range nodes {
+						if p == e.path || strings.HasPrefix(p, e.path+"/") {
+							delete(nodes, p)
+						}
+					}
+				}
+				continue
+			}
+
+			if err := arm(e.path, true); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type persistentWatchNode struct {
+	exists   bool
+	children bool
+}
@@ -0,0 +1,127 @@
+package curator
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+const persistentWatchPath = "/test/persistentWatch"
+
+func TestZookeeperClient_AddPersistentWatch(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal("failed to newZookeeperClient, err:", err)
+	}
+	defer client.Close()
+
+	DeleteAll(client, persistentWatchPath)
+	if _, err := CreateAll(client, persistentWatchPath, nil, 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to CreateAll, err:", err)
+	}
+	defer DeleteAll(client, persistentWatchPath)
+
+	c := make(chan zk.Event, 10)
+	watch, err := client.AddPersistentWatch(persistentWatchPath, true, func(event zk.Event) {
+		c <- event
+	})
+	if err != nil {
+		t.Fatal("failed to AddPersistentWatch, err:", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	expectEvent := func(expectedType zk.EventType, expectedPath string) {
+		select {
+		case event := <-c:
+			if event.Type != expectedType || event.Path != expectedPath {
+				t.Fatal("unexpected event:", event.Type, event.Path)
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatal("deadline waiting for", expectedType, expectedPath)
+		}
+	}
+
+	node := path.Join(persistentWatchPath, "a")
+	if _, err := client.Create(node, nil, 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to client.Create, err:", err)
+	}
+	expectEvent(zk.EventNodeCreated, node)
+
+	if _, err := client.Set(node, []byte("a"), -1); err != nil {
+		t.Fatal("failed to client.Set, err:", err)
+	}
+	expectEvent(zk.EventNodeDataChanged, node)
+
+	deepNode := path.Join(node, "b")
+	if _, err := client.Create(deepNode, nil, 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to client.Create, err:", err)
+	}
+	expectEvent(zk.EventNodeCreated, deepNode)
+
+	if err := client.Delete(deepNode, -1); err != nil {
+		t.Fatal("failed to client.Delete, err:", err)
+	}
+	expectEvent(zk.EventNodeDeleted, deepNode)
+
+	if err := watch.Remove(); err != nil {
+		t.Fatal("failed to watch.Remove, err:", err)
+	}
+	if _, err := client.Set(node, []byte("b"), -1); err != nil {
+		t.Fatal("failed to client.Set, err:", err)
+	}
+	select {
+	case event := <-c:
+		t.Fatal("unexpected event after Remove:", event.Type, event.Path)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestZookeeperClient_AddPersistentWatchNamespace(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal("failed to newZookeeperClient, err:", err)
+	}
+	defer client.Close()
+
+	view := client.UsingNamespace("test-namespace")
+	DeleteAll(view, persistentWatchPath)
+	if _, err := CreateAll(view, persistentWatchPath, nil, 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to CreateAll, err:", err)
+	}
+	defer DeleteAll(view, persistentWatchPath)
+
+	c := make(chan zk.Event, 10)
+	watch, err := view.AddPersistentWatch(persistentWatchPath, true, func(event zk.Event) {
+		c <- event
+	})
+	if err != nil {
+		t.Fatal("failed to AddPersistentWatch, err:", err)
+	}
+	defer watch.Remove()
+	time.Sleep(100 * time.Millisecond)
+
+	expectEvent := func(expectedType zk.EventType, expectedPath string) {
+		select {
+		case event := <-c:
+			if event.Type != expectedType || event.Path != expectedPath {
+				t.Fatal("unexpected event:", event.Type, event.Path)
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatal("deadline waiting for", expectedType, expectedPath)
+		}
+	}
+
+	// events carry the paths of the view, whichever client made the change
+	node := path.Join(persistentWatchPath, "a")
+	if _, err := client.Create(path.Join("/test-namespace", node), nil, 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to client.Create, err:", err)
+	}
+	expectEvent(zk.EventNodeCreated, node)
+
+	if _, err := view.Set(node, []byte("a"), -1); err != nil {
+		t.Fatal("failed to view.Set, err:", err)
+	}
+	expectEvent(zk.EventNodeDataChanged, node)
+}
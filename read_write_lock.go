@@ -0,0 +1,50 @@
+package curator
+
+import (
+	"strings"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+const (
+	readLockName  = "__READ__"
+	writeLockName = "__WRIT__"
+)
+
+// ReadWriteLock shares basePath between readers and writers. Read lock nodes
+// only wait for preceding write lock nodes, write lock nodes wait for every
+// preceding node. A holder of the write lock may acquire the read lock and
+// then release the write lock to downgrade, upgrading is not possible.
+type ReadWriteLock struct {
+	readMutex  *Mutex
+	writeMutex *Mutex
+}
+
+func NewReadWriteLock(client *ZookeeperClient, basePath string, aclv []zk.ACL) *ReadWriteLock {
+	l := &ReadWriteLock{}
+	// both lock names end with "__", sorting on it orders reads and writes together
+	l.writeMutex = newMutex(client, basePath, writeLockName, "__", standardLockPredicate, aclv)
+	l.readMutex = newMutex(client, basePath, readLockName, "__", l.readLockPredicate, aclv)
+	return l
+}
+
+func (l *ReadWriteLock) ReadLock() *Mutex {
+	return l.readMutex
+}
+
+func (l *ReadWriteLock) WriteLock() *Mutex {
+	return l.writeMutex
+}
+
+func (l *ReadWriteLock) readLockPredicate(children []string, index int) (bool, string) {
+	if l.writeMutex.IsAcquiredInThisProcess() {
+		return true, ""
+	}
+
+	for i := index - 1; i >= 0; i-- {
+		if strings.Contains(children[i], writeLockName) {
+			return false, children[i]
+		}
+	}
+	return true, ""
+}
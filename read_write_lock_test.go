@@ -0,0 +1,52 @@
+package curator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestReadWriteLock(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	basePath := "/test/read_write_lock_test"
+
+	reader := NewReadWriteLock(client, basePath, zk.WorldACL(zk.PermAll))
+	participantReader := NewReadWriteLock(client, basePath, zk.WorldACL(zk.PermAll))
+	if err := reader.ReadLock().Acquire(); err != nil {
+		t.Fatal("failed to ReadLock().Acquire, err:", err)
+	}
+	if err := participantReader.ReadLock().AcquireTimeout(1 * time.Second); err != nil {
+		t.Fatal("readers must share the lock, err:", err)
+	}
+
+	writer := NewReadWriteLock(client, basePath, zk.WorldACL(zk.PermAll))
+	if err := writer.WriteLock().AcquireTimeout(100 * time.Millisecond); err == nil {
+		t.Fatal("writer must wait for readers")
+	}
+
+	reader.ReadLock().Release()
+	participantReader.ReadLock().Release()
+	if err := writer.WriteLock().AcquireTimeout(1 * time.Second); err != nil {
+		t.Fatal("failed to WriteLock().Acquire, err:", err)
+	}
+	if err := reader.ReadLock().AcquireTimeout(100 * time.Millisecond); err == nil {
+		t.Fatal("reader must wait for writer")
+	}
+
+	// downgrade
+	if err := writer.ReadLock().AcquireTimeout(1 * time.Second); err != nil {
+		t.Fatal("writer failed to downgrade, err:", err)
+	}
+	writer.WriteLock().Release()
+	if err := reader.ReadLock().AcquireTimeout(1 * time.Second); err != nil {
+		t.Fatal("reader failed to acquire after downgrade, err:", err)
+	}
+	reader.ReadLock().Release()
+	writer.ReadLock().Release()
+}
@@ -1,21 +1,50 @@
 package curator
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
 )
 
+type ContextError struct {
+	Err     error
+	LastErr error
+}
+
+func (e *ContextError) Error() string {
+	if e.LastErr == nil {
+		return "curator: " + e.Err.Error()
+	}
+	return "curator: " + e.Err.Error() + ", last error: " + e.LastErr.Error()
+}
+
+func (e *ContextError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the last ZooKeeper error, the context error is matched through
+// Unwrap.
+func (e *ContextError) Is(target error) bool {
+	return e.LastErr != nil && errors.Is(e.LastErr, target)
+}
+
 type defaultSleeper struct {
+	ctx    context.Context
 	client *ZookeeperClient
 }
 
 func (s defaultSleeper) Sleep(duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
 	select {
 	case <-s.client.quit:
 		return errors.New("curator: ZookeeperClient had been closed")
-	case <-time.After(duration):
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	case <-timer.C:
 		return nil
 	}
 }
@@ -30,14 +59,20 @@ func ShouldRetry(err error) bool {
 }
 
 func CallWithRetryLoop(client *ZookeeperClient, operate func() error) (err error) {
+	return CallWithRetryLoopCtx(context.Background(), client, operate)
+}
+
+func CallWithRetryLoopCtx(ctx context.Context, client *ZookeeperClient, operate func() error) (err error) {
 	var (
 		startTime = time.Now()
 		policy    = client.GetRetryPolicy()
-		sleeper   = defaultSleeper{client}
+		sleeper   = defaultSleeper{ctx, client}
 	)
 
 	for count := 0; ; count++ {
-		client.BlockUntilConnectedOrTimeout()
+		if ctxErr := client.BlockUntilConnectedOrTimeoutCtx(ctx); ctxErr != nil {
+			return &ContextError{Err: ctxErr, LastErr: err}
+		}
 
 		err = operate()
 		if !ShouldRetry(err) {
@@ -45,6 +80,9 @@ func CallWithRetryLoop(client *ZookeeperClient, operate func() error) (err error
 		}
 
 		if !policy.AllowRetry(count, time.Since(startTime), sleeper) {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return &ContextError{Err: ctxErr, LastErr: err}
+			}
 			break
 		}
 	}
@@ -0,0 +1,208 @@
+package curator
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+const (
+	semaphoreLockName  = "lock-"
+	semaphoreLeaseName = "lease-"
+)
+
+// Semaphore allows up to maxLeases holders across processes. Leases are
+// ephemeral nodes under basePath/leases, so a crashed holder frees its slots
+// when its session expires.
+type Semaphore struct {
+	client    *ZookeeperClient
+	basePath  string
+	maxLeases int
+	countPath string
+	aclv      []zk.ACL
+}
+
+type Lease struct {
+	client   *ZookeeperClient
+	nodePath string
+}
+
+func (l *Lease) Path() string {
+	return l.nodePath
+}
+
+func (l *Lease) Release() error {
+	err := l.client.Delete(l.nodePath, -1)
+	if err == zk.ErrNoNode {
+		return nil
+	}
+	return err
+}
+
+func NewSemaphore(client *ZookeeperClient, basePath string, maxLeases int, aclv []zk.ACL) *Semaphore {
+	return &Semaphore{
+		client:    client,
+		basePath:  basePath,
+		maxLeases: maxLeases,
+		aclv:      aclv,
+	}
+}
+
+// NewSemaphoreWithSharedCount reads the max lease count from the decimal
+// data of countPath, so all participants share the same limit.
+func NewSemaphoreWithSharedCount(client *ZookeeperClient, basePath string, countPath string, aclv []zk.ACL) *Semaphore {
+	return &Semaphore{
+		client:    client,
+		basePath:  basePath,
+		countPath: countPath,
+		aclv:      aclv,
+	}
+}
+
+func (s *Semaphore) Acquire(n int) ([]*Lease, error) {
+	return s.AcquireCtx(context.Background(), n)
+}
+
+func (s *Semaphore) AcquireTimeout(timeout time.Duration, n int) ([]*Lease, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return s.AcquireCtx(ctx, n)
+}
+
+func (s *Semaphore) AcquireCtx(ctx context.Context, n int) ([]*Lease, error) {
+	if n <= 0 {
+		return nil, errors.New("curator: Semaphore lease count must be positive")
+	}
+
+	leases := make([]*Lease, 0, n)
+	for i := 0; i < n; i++ {
+		lease, err := s.acquireLease(ctx)
+		if err != nil {
+			ReleaseLeases(leases)
+			return nil, err
+		}
+		leases = append(leases, lease)
+	}
+	return leases, nil
+}
+
+func (s *Semaphore) GetParticipantNodes() ([]string, error) {
+	children, _, err := s.client.Children(path.Join(s.basePath, "leases"))
+	if err != nil {
+		if err == zk.ErrNoNode {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	children = sortLockChildren(children, semaphoreLeaseName)
+	for i, child := range children {
+		children[i] = path.Join(s.basePath, "leases", child)
+	}
+	return children, nil
+}
+
+func ReleaseLeases(leases []*Lease) {
+	for _, lease := range leases {
+		if err := lease.Release(); err != nil {
+			Log.Warnln("curator: failed to release lease, path:", lease.nodePath, "err:", err)
+		}
+	}
+}
+
+func (s *Semaphore) acquireLease(ctx context.Context) (*Lease, error) {
+	// a Mutex per lease serializes the participants while they wait for a slot
+	mutex := NewMutex(s.client, path.Join(s.basePath, "locks"), s.aclv)
+	if err := mutex.AcquireCtx(ctx); err != nil {
+		return nil, err
+	}
+	defer mutex.Release()
+
+	leasesPath := path.Join(s.basePath, "leases")
+	nodePath, err := createTheLock(ctx, s.client, path.Join(leasesPath, semaphoreLeaseName), []byte{}, s.aclv)
+	if err != nil {
+		return nil, err
+	}
+	lease := &Lease{client: s.client, nodePath: nodePath}
+
+	// a watch is registered again only after it fired, otherwise every wakeup
+	// would leave another one behind in the client
+	var (
+		maxLeases int
+		children  []string
+		countChan <-chan zk.Event
+		leaseChan <-chan zk.Event
+		readCount = true
+		readLease = true
+	)
+	for {
+		if readCount {
+			maxLeases, countChan, err = s.getMaxLeases(ctx)
+			if err != nil {
+				lease.Release()
+				return nil, err
+			}
+			readCount = false
+		}
+
+		if readLease {
+			children, _, leaseChan, err = s.client.ChildrenWCtx(ctx, leasesPath)
+			if err != nil {
+				lease.Release()
+				return nil, err
+			}
+			children = sortLockChildren(children, semaphoreLeaseName)
+			readLease = false
+
+			found := false
+			for _, child := range children {
+				if child == path.Base(nodePath) {
+					found = true
+					break
+				}
+			}
+			// the lease node had been removed, maybe the session expired
+			if !found {
+				return nil, zk.ErrNoNode
+			}
+		}
+
+		if len(children) <= maxLeases {
+			return lease, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			lease.Release()
+			return nil, ctx.Err()
+		case <-leaseChan:
+			readLease = true
+		case <-countChan:
+			readCount = true
+		}
+	}
+}
+
+func (s *Semaphore) getMaxLeases(ctx context.Context) (int, <-chan zk.Event, error) {
+	if s.countPath == "" {
+		if s.maxLeases <= 0 {
+			return 0, nil, errors.New("curator: Semaphore max leases must be positive")
+		}
+		return s.maxLeases, nil, nil
+	}
+
+	data, _, countChan, err := s.client.GetWCtx(ctx, s.countPath)
+	if err != nil {
+		return 0, nil, err
+	}
+	maxLeases, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || maxLeases <= 0 {
+		return 0, nil, errors.New("curator: invalid Semaphore max leases, path: " + s.countPath)
+	}
+	return maxLeases, countChan, nil
+}
@@ -0,0 +1,97 @@
+package curator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestSemaphore(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	basePath := "/test/semaphore_test"
+	defer DeleteAll(client, basePath)
+
+	semaphore := NewSemaphore(client, basePath, 3, zk.WorldACL(zk.PermAll))
+	leases, err := semaphore.Acquire(2)
+	if err != nil {
+		t.Fatal("failed to semaphore.Acquire, err:", err)
+	}
+	if len(leases) != 2 {
+		t.Fatal("unexpected leases:", leases)
+	}
+
+	participant := NewSemaphore(client, basePath, 3, zk.WorldACL(zk.PermAll))
+	if _, err := participant.AcquireTimeout(100*time.Millisecond, 2); err == nil {
+		t.Fatal("participant must not exceed max leases")
+	}
+	nodes, err := semaphore.GetParticipantNodes()
+	if err != nil || len(nodes) != 2 {
+		t.Fatal("unexpected participant nodes:", nodes, "err:", err)
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		leases, err := participant.Acquire(2)
+		if err == nil {
+			ReleaseLeases(leases)
+		}
+		ch <- err
+	}()
+
+	leases[0].Release()
+	select {
+	case err := <-ch:
+		if err != nil {
+			t.Fatal("participant failed to acquire leases, err:", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("participant cannot acquire leases")
+	}
+	ReleaseLeases(leases[1:])
+}
+
+func TestSemaphore_SharedCount(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	basePath := "/test/semaphore_test"
+	countPath := "/test/semaphore_count"
+	defer DeleteAll(client, basePath)
+	if _, err := CreateAll(client, countPath, []byte("1"), 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to CreateAll, err:", err)
+	}
+	defer DeleteAll(client, countPath)
+
+	semaphore := NewSemaphoreWithSharedCount(client, basePath, countPath, zk.WorldACL(zk.PermAll))
+	leases, err := semaphore.Acquire(1)
+	if err != nil {
+		t.Fatal("failed to semaphore.Acquire, err:", err)
+	}
+	defer ReleaseLeases(leases)
+
+	ch := make(chan error, 1)
+	go func() {
+		leases, err := semaphore.AcquireTimeout(2*time.Second, 1)
+		if err == nil {
+			ReleaseLeases(leases)
+		}
+		ch <- err
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	if _, err := client.Set(countPath, []byte("2"), -1); err != nil {
+		t.Fatal("failed to client.Set, err:", err)
+	}
+	if err := <-ch; err != nil {
+		t.Fatal("failed to acquire lease after raising count, err:", err)
+	}
+}
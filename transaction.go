@@ -0,0 +1,94 @@
+package curator
+
+import (
+	"context"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+type TransactionOpType int
+
+const (
+	TransactionCreate  TransactionOpType = 1
+	TransactionSetData TransactionOpType = 2
+	TransactionCheck   TransactionOpType = 3
+	TransactionDelete  TransactionOpType = 4
+)
+
+func (t TransactionOpType) String() string {
+	switch t {
+	case TransactionCreate:
+		return "TransactionCreate"
+	case TransactionSetData:
+		return "TransactionSetData"
+	case TransactionCheck:
+		return "TransactionCheck"
+	case TransactionDelete:
+		return "TransactionDelete"
+	}
+	return "unknown"
+}
+
+type TransactionResult struct {
+	Type       TransactionOpType
+	ForPath    string
+	ResultPath string
+	Stat       *zk.Stat
+	Err        error
+}
+
+type Transaction struct {
+	client *ZookeeperClient
+	types  []TransactionOpType
+	paths  []string
+	ops    []interface{}
+}
+
+func (c *ZookeeperClient) InTransaction() *Transaction {
+	return &Transaction{client: c}
+}
+
+func (t *Transaction) add(opType TransactionOpType, path string, op interface{}) *Transaction {
+	t.types = append(t.types, opType)
+	t.paths = append(t.paths, path)
+	t.ops = append(t.ops, op)
+	return t
+}
+
+func (t *Transaction) Create(path string, data []byte, flags int32, aclv []zk.ACL) *Transaction {
+	return t.add(TransactionCreate, path, &zk.CreateRequest{Path: path, Data: data, Acl: aclv, Flags: flags})
+}
+
+func (t *Transaction) SetData(path string, data []byte, version int32) *Transaction {
+	return t.add(TransactionSetData, path, &zk.SetDataRequest{Path: path, Data: data, Version: version})
+}
+
+func (t *Transaction) Check(path string, version int32) *Transaction {
+	return t.add(TransactionCheck, path, &zk.CheckVersionRequest{Path: path, Version: version})
+}
+
+func (t *Transaction) Delete(path string, version int32) *Transaction {
+	return t.add(TransactionDelete, path, &zk.DeleteRequest{Path: path, Version: version})
+}
+
+func (t *Transaction) Commit() ([]TransactionResult, error) {
+	return t.CommitCtx(context.Background())
+}
+
+func (t *Transaction) CommitCtx(ctx context.Context) ([]TransactionResult, error) {
+	responses, err := t.client.MultiCtx(ctx, t.ops...)
+	if len(responses) == 0 {
+		return nil, err
+	}
+
+	results := make([]TransactionResult, len(t.ops))
+	for i := range t.ops {
+		results[i] = TransactionResult{Type: t.types[i], ForPath: t.paths[i]}
+		if i < len(responses) {
+			results[i].ResultPath = responses[i].String
+			results[i].Stat = responses[i].Stat
+			results[i].Err = responses[i].Error
+		}
+	}
+	return results, err
+}
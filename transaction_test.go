@@ -0,0 +1,60 @@
+package curator
+
+import (
+	"path"
+	"testing"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+const transactionTestNode = "/test/transaction"
+
+func TestTransaction_Commit(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal("failed to newZookeeperClient, err:", err)
+	}
+	defer client.Close()
+
+	counterNode := path.Join(transactionTestNode, "counter")
+	if _, err := CreateAll(client, counterNode, []byte("0"), 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to CreateAll, err:", err)
+	}
+	defer DeleteAll(client, transactionTestNode)
+
+	_, stat, err := client.Get(counterNode)
+	if err != nil {
+		t.Fatal("failed to client.Get, err:", err)
+	}
+
+	childNode := path.Join(transactionTestNode, "child")
+	results, err := client.InTransaction().
+		Check(counterNode, stat.Version).
+		Create(childNode, []byte("child"), 0, zk.WorldACL(zk.PermAll)).
+		SetData(counterNode, []byte("1"), stat.Version).
+		Delete(childNode, -1).
+		Commit()
+	if err != nil {
+		t.Fatal("failed to Commit, err:", err)
+	}
+	if len(results) != 4 {
+		t.Fatal("unexpected results:", results)
+	}
+	if results[1].Type != TransactionCreate || results[1].ResultPath != childNode {
+		t.Fatal("unexpected create result:", results[1])
+	}
+	if results[2].Type != TransactionSetData || results[2].Stat == nil || results[2].Stat.Version != stat.Version+1 {
+		t.Fatal("unexpected set data result:", results[2])
+	}
+
+	_, err = client.InTransaction().
+		Create(childNode, nil, 0, zk.WorldACL(zk.PermAll)).
+		Check(counterNode, stat.Version).
+		Commit()
+	if err == nil {
+		t.Fatal("Commit must fail on version mismatch")
+	}
+	if exist, _, _ := client.Exists(childNode); exist {
+		t.Fatal("failed transaction must not create node")
+	}
+}
@@ -0,0 +1,377 @@
+package curator
+
+import (
+	"errors"
+	"math"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+type TreeCacheEvent struct {
+	Path string
+	Data []byte
+	Stat *zk.Stat
+	Type TreeCacheEventType
+}
+
+type TreeCacheEventType int
+
+const (
+	TreeCacheNodeAdded      TreeCacheEventType = 1
+	TreeCacheNodeUpdated    TreeCacheEventType = 2
+	TreeCacheNodeRemoved    TreeCacheEventType = 3
+	TreeCacheInitialized    TreeCacheEventType = 4
+	TreeCacheConnectionLost TreeCacheEventType = 5
+	TreeCacheReconnected    TreeCacheEventType = 6
+)
+
+func (t TreeCacheEventType) String() string {
+	switch t {
+	case TreeCacheNodeAdded:
+		return "TreeCacheNodeAdded"
+	case TreeCacheNodeUpdated:
+		return "TreeCacheNodeUpdated"
+	case TreeCacheNodeRemoved:
+		return "TreeCacheNodeRemoved"
+	case TreeCacheInitialized:
+		return "TreeCacheInitialized"
+	case TreeCacheConnectionLost:
+		return "TreeCacheConnectionLost"
+	case TreeCacheReconnected:
+		return "TreeCacheReconnected"
+	}
+	return "unknown"
+}
+
+type OnTreeCacheChange func(event TreeCacheEvent)
+
+type ChildData struct {
+	Path string
+	Data []byte
+	Stat *zk.Stat
+}
+
+type treeNode struct {
+	path     string
+	depth    int
+	parent   *treeNode
+	done     chan struct{}
+	once     sync.Once
+	data     []byte
+	stat     *zk.Stat
+	children map[string]*treeNode
+}
+
+func newTreeNode(nodePath string, depth int, parent *treeNode) *treeNode {
+	return &treeNode{
+		path:     nodePath,
+		depth:    depth,
+		parent:   parent,
+		done:     make(chan struct{}),
+		children: make(map[string]*treeNode),
+	}
+}
+
+func (n *treeNode) stop() {
+	n.once.Do(func() { close(n.done) })
+}
+
+type TreeCache struct {
+	start          int32
+	client         *ZookeeperClient
+	root           string
+	maxDepth       int
+	callback       OnTreeCacheChange
+	mutex          sync.RWMutex
+	nodes          map[string]*treeNode
+	notifyMutex    sync.Mutex
+	outstanding    int32
+	initialized    int32
+	connectionLost int32
+	quit           chan struct{}
+	wg             sync.WaitGroup
+}
+
+var _ ConnectionStateListener = &TreeCache{}
+
+func NewTreeCache(client *ZookeeperClient, root string, callback OnTreeCacheChange) *TreeCache {
+	return &TreeCache{
+		client:   client,
+		root:     root,
+		maxDepth: math.MaxInt32,
+		callback: callback,
+	}
+}
+
+func (t *TreeCache) WithMaxDepth(maxDepth int) *TreeCache {
+	t.maxDepth = maxDepth
+	return t
+}
+
+func (t *TreeCache) Start() error {
+	if !atomic.CompareAndSwapInt32(&t.start, 0, 1) {
+		return errors.New("curator: TreeCache already started")
+	}
+
+	t.mutex.Lock()
+	t.nodes = make(map[string]*treeNode)
+	t.mutex.Unlock()
+	atomic.StoreInt32(&t.outstanding, 0)
+	atomic.StoreInt32(&t.initialized, 0)
+	atomic.StoreInt32(&t.connectionLost, 0)
+
+	t.client.GetConnectionStateListenable().AddListener(t)
+
+	t.quit = make(chan struct{}, 1)
+	t.watch(newTreeNode(t.root, 0, nil))
+	return nil
+}
+
+func (t *TreeCache) Close() error {
+	if !atomic.CompareAndSwapInt32(&t.start, 1, 0) {
+		return errors.New("curator: TreeCache already closed")
+	}
+
+	t.client.GetConnectionStateListenable().RemoveListener(t)
+	close(t.quit)
+	t.wg.Wait()
+	return nil
+}
+
+func (t *TreeCache) GetCurrentData(nodePath string) (data []byte, stat *zk.Stat, ok bool) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	node := t.nodes[nodePath]
+	if node == nil {
+		return
+	}
+	return node.data, node.stat, true
+}
+
+func (t *TreeCache) GetCurrentChildren(nodePath string) map[string]ChildData {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	node := t.nodes[nodePath]
+	if node == nil {
+		return nil
+	}
+
+	children := make(map[string]ChildData, len(node.children))
+	for name, child := range node.children {
+		if child.stat != nil {
+			children[name] = ChildData{Path: child.path, Data: child.data, Stat: child.stat}
+		}
+	}
+	return children
+}
+
+func (t *TreeCache) watch(node *treeNode) {
+	atomic.AddInt32(&t.outstanding, 1)
+	t.wg.Add(1)
+	go t.watchNode(node)
+}
+
+func (t *TreeCache) watchNode(node *treeNode) {
+	Log.Infoln("curator: start TreeCache.watchNode", node.path)
+	defer func() {
+		Log.Infoln("curator: stop TreeCache.watchNode", node.path)
+		t.wg.Done()
+	}()
+
+	var dataChan, childChan <-chan zk.Event
+	first := true
+	for {
+		var err error
+		if dataChan == nil {
+			var data []byte
+			var stat *zk.Stat
+			data, stat, dataChan, err = t.client.GetW(node.path)
+			if err == nil {
+				t.updateNode(node, data, stat)
+			}
+		}
+		if err == nil && childChan == nil && node.depth < t.maxDepth {
+			var children []string
+			children, _, childChan, err = t.client.ChildrenW(node.path)
+			if err == nil {
+				t.updateChildren(node, children)
+			}
+		}
+
+		if err == zk.ErrNoNode {
+			t.removeNode(node)
+			if node.parent != nil {
+				t.outstandingDone(&first)
+				return
+			}
+
+			// the root may come back, so wait for it
+			dataChan, childChan = nil, nil
+			var exist bool
+			var existChan <-chan zk.Event
+			exist, _, existChan, err = t.client.ExistsW(node.path)
+			if err == nil {
+				if !exist {
+					t.outstandingDone(&first)
+					select {
+					case <-t.quit:
+						return
+					case <-existChan:
+					}
+				}
+				continue
+			}
+		}
+
+		if err != nil {
+			Log.Errorln("curator: TreeCache failed to refresh, node:", node.path, "err:", err)
+			dataChan, childChan = nil, nil
+			select {
+			case <-t.quit:
+				return
+			case <-node.done:
+				return
+			case <-time.After(cacheRetryInterval):
+			}
+			continue
+		}
+
+		t.outstandingDone(&first)
+
+		select {
+		case <-t.quit:
+			return
+		case <-node.done:
+			return
+		case <-dataChan:
+			dataChan = nil
+		case <-childChan:
+			childChan = nil
+		}
+	}
+}
+
+func (t *TreeCache) outstandingDone(first *bool) {
+	if !*first {
+		return
+	}
+	*first = false
+
+	if atomic.AddInt32(&t.outstanding, -1) == 0 && atomic.CompareAndSwapInt32(&t.initialized, 0, 1) {
+		t.notify(TreeCacheEvent{Path: t.root, Type: TreeCacheInitialized})
+	}
+}
+
+func (t *TreeCache) updateNode(node *treeNode, data []byte, stat *zk.Stat) {
+	t.mutex.Lock()
+	select {
+	case <-node.done:
+		t.mutex.Unlock()
+		return
+	default:
+	}
+	oldStat := node.stat
+	node.data, node.stat = data, stat
+	t.nodes[node.path] = node
+	if node.parent != nil {
+		node.parent.children[path.Base(node.path)] = node
+	}
+	t.mutex.Unlock()
+
+	if oldStat == nil {
+		t.notify(TreeCacheEvent{Path: node.path, Data: data, Stat: stat, Type: TreeCacheNodeAdded})
+	} else if oldStat.Mzxid != stat.Mzxid || oldStat.Czxid != stat.Czxid {
+		t.notify(TreeCacheEvent{Path: node.path, Data: data, Stat: stat, Type: TreeCacheNodeUpdated})
+	}
+}
+
+func (t *TreeCache) updateChildren(node *treeNode, children []string) {
+	current := make(map[string]bool, len(children))
+	for _, child := range children {
+		current[child] = true
+	}
+
+	var added, removed []*treeNode
+	t.mutex.Lock()
+	for name, child := range node.children {
+		if !current[name] {
+			removed = append(removed, child)
+		}
+	}
+	for _, name := range children {
+		if _, ok := node.children[name]; !ok {
+			child := newTreeNode(path.Join(node.path, name), node.depth+1, node)
+			node.children[name] = child
+			added = append(added, child)
+		}
+	}
+	t.mutex.Unlock()
+
+	for _, child := range removed {
+		t.removeNode(child)
+	}
+	for _, child := range added {
+		t.watch(child)
+	}
+}
+
+func (t *TreeCache) removeNode(node *treeNode) {
+	var removed []*treeNode
+	var collect func(n *treeNode)
+	collect = func(n *treeNode) {
+		for _, child := range n.children {
+			collect(child)
+			child.stop()
+		}
+		n.children = make(map[string]*treeNode)
+		if n.stat != nil {
+			removed = append(removed, n)
+			n.data, n.stat = nil, nil
+		}
+		delete(t.nodes, n.path)
+	}
+
+	t.mutex.Lock()
+	collect(node)
+	if node.parent != nil {
+		name := path.Base(node.path)
+		if node.parent.children[name] == node {
+			delete(node.parent.children, name)
+		}
+		node.stop()
+	}
+	t.mutex.Unlock()
+
+	for _, n := range removed {
+		t.notify(TreeCacheEvent{Path: n.path, Type: TreeCacheNodeRemoved})
+	}
+}
+
+func (t *TreeCache) StateChanged(client *ZookeeperClient, state ConnectionState) {
+	switch state {
+	case ConnectionStateSuspended, ConnectionStateLost:
+		if atomic.CompareAndSwapInt32(&t.connectionLost, 0, 1) {
+			t.notify(TreeCacheEvent{Path: t.root, Type: TreeCacheConnectionLost})
+		}
+	case ConnectionStateConnected, ConnectionStateReconnected:
+		if atomic.CompareAndSwapInt32(&t.connectionLost, 1, 0) {
+			t.notify(TreeCacheEvent{Path: t.root, Type: TreeCacheReconnected})
+		}
+	}
+}
+
+func (t *TreeCache) notify(event TreeCacheEvent) {
+	Log.Infoln("curator: TreeCache node:", event.Path, "event:", event.Type)
+
+	if t.callback != nil {
+		t.notifyMutex.Lock()
+		t.callback(event)
+		t.notifyMutex.Unlock()
+	}
+}
@@ -0,0 +1,121 @@
+package curator
+
+import (
+	"bytes"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+const treeCacheNode = "/test/treeCache"
+
+func waitTreeCacheEvent(t *testing.T, c <-chan TreeCacheEvent, expectedType TreeCacheEventType, expectedPath string) TreeCacheEvent {
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case event := <-c:
+			if event.Type == expectedType && event.Path == expectedPath {
+				return event
+			}
+		case <-deadline:
+			t.Fatal("deadline waiting for", expectedType, expectedPath)
+		}
+	}
+}
+
+func TestTreeCache(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal("failed to newZookeeperClient, err:", err)
+	}
+	defer client.Close()
+
+	DeleteAll(client, treeCacheNode)
+	deepNode := path.Join(treeCacheNode, "a", "b")
+	if _, err := CreateAll(client, deepNode, []byte("b"), 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to CreateAll, err:", err)
+	}
+	defer DeleteAll(client, treeCacheNode)
+
+	c := make(chan TreeCacheEvent, 100)
+	cache := NewTreeCache(client, treeCacheNode, func(event TreeCacheEvent) {
+		c <- event
+	})
+	if err := cache.Start(); err != nil {
+		t.Fatal("failed to cache.Start, err:", err)
+	}
+	defer cache.Close()
+
+	waitTreeCacheEvent(t, c, TreeCacheInitialized, treeCacheNode)
+
+	data, _, ok := cache.GetCurrentData(deepNode)
+	if !ok || !bytes.Equal(data, []byte("b")) {
+		t.Fatal("unexpected data of", deepNode, string(data))
+	}
+	children := cache.GetCurrentChildren(path.Join(treeCacheNode, "a"))
+	if _, ok := children["b"]; !ok || len(children) != 1 {
+		t.Fatal("unexpected children:", children)
+	}
+
+	newNode := path.Join(deepNode, "c")
+	if _, err := client.Create(newNode, []byte("c"), 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to client.Create, err:", err)
+	}
+	waitTreeCacheEvent(t, c, TreeCacheNodeAdded, newNode)
+
+	if _, err := client.Set(newNode, []byte("c2"), -1); err != nil {
+		t.Fatal("failed to client.Set, err:", err)
+	}
+	event := waitTreeCacheEvent(t, c, TreeCacheNodeUpdated, newNode)
+	if !bytes.Equal(event.Data, []byte("c2")) {
+		t.Fatal("unexpected updated data:", string(event.Data))
+	}
+
+	if err := DeleteAll(client, treeCacheNode); err != nil {
+		t.Fatal("failed to DeleteAll, err:", err)
+	}
+	waitTreeCacheEvent(t, c, TreeCacheNodeRemoved, treeCacheNode)
+	if _, _, ok := cache.GetCurrentData(newNode); ok {
+		t.Fatal("unexpected data of removed node")
+	}
+
+	if _, err := CreateAll(client, treeCacheNode, nil, 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to CreateAll, err:", err)
+	}
+	waitTreeCacheEvent(t, c, TreeCacheNodeAdded, treeCacheNode)
+}
+
+func TestTreeCache_MaxDepth(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal("failed to newZookeeperClient, err:", err)
+	}
+	defer client.Close()
+
+	DeleteAll(client, treeCacheNode)
+	deepNode := path.Join(treeCacheNode, "a", "b")
+	if _, err := CreateAll(client, deepNode, nil, 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to CreateAll, err:", err)
+	}
+	defer DeleteAll(client, treeCacheNode)
+
+	c := make(chan TreeCacheEvent, 100)
+	cache := NewTreeCache(client, treeCacheNode, func(event TreeCacheEvent) {
+		c <- event
+	}).WithMaxDepth(1)
+	if err := cache.Start(); err != nil {
+		t.Fatal("failed to cache.Start, err:", err)
+	}
+	defer cache.Close()
+
+	waitTreeCacheEvent(t, c, TreeCacheInitialized, treeCacheNode)
+
+	if _, _, ok := cache.GetCurrentData(path.Join(treeCacheNode, "a")); !ok {
+		t.Fatal("node within max depth must be cached")
+	}
+	if _, _, ok := cache.GetCurrentData(deepNode); ok {
+		t.Fatal("node beyond max depth must not be cached")
+	}
+}
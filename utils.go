@@ -1,13 +1,18 @@
 package curator
 
 import (
+	"context"
 	"path"
 
 	"github.com/samuel/go-zookeeper/zk"
 )
 
 func CreateAll(client *ZookeeperClient, nodePath string, value []byte, flags int32, aclv []zk.ACL) (string, error) {
-	if exists, _, err := client.Exists(nodePath); exists && err == nil {
+	return CreateAllCtx(context.Background(), client, nodePath, value, flags, aclv)
+}
+
+func CreateAllCtx(ctx context.Context, client *ZookeeperClient, nodePath string, value []byte, flags int32, aclv []zk.ACL) (string, error) {
+	if exists, _, err := client.ExistsCtx(ctx, nodePath); exists && err == nil {
 		return nodePath, zk.ErrNodeExists
 	}
 
@@ -23,14 +28,14 @@ func CreateAll(client *ZookeeperClient, nodePath string, value []byte, flags int
 
 	if j > 1 {
 		// Create parent
-		if _, err := CreateAll(client, nodePath[0:j-1], []byte{}, 0, aclv); err != nil {
+		if _, err := CreateAllCtx(ctx, client, nodePath[0:j-1], []byte{}, 0, aclv); err != nil {
 			if err != zk.ErrNodeExists {
 				return "", err
 			}
 		}
 	}
 
-	return client.Create(nodePath, value, flags, aclv)
+	return client.CreateCtx(ctx, nodePath, value, flags, aclv)
 }
 
 func DeleteAll(client *ZookeeperClient, node string) error {
@@ -6,51 +6,195 @@ import (
 	"github.com/samuel/go-zookeeper/zk"
 )
 
+const DefaultWatcherQueueSize = 16
+
+type WatcherOverflowPolicy int
+
+const (
+	// WatcherOverflowBlock blocks the dispatcher until the queue has room, so
+	// a slow watcher stalls the connection events of every watcher again.
+	WatcherOverflowBlock WatcherOverflowPolicy = 0
+	// WatcherOverflowDrop drops the new event.
+	WatcherOverflowDrop WatcherOverflowPolicy = 1
+	// WatcherOverflowCoalesce drops the oldest queued event to keep the latest,
+	// it is the default.
+	WatcherOverflowCoalesce WatcherOverflowPolicy = 2
+)
+
+func (p WatcherOverflowPolicy) String() string {
+	switch p {
+	case WatcherOverflowBlock:
+		return "WatcherOverflowBlock"
+	case WatcherOverflowDrop:
+		return "WatcherOverflowDrop"
+	case WatcherOverflowCoalesce:
+		return "WatcherOverflowCoalesce"
+	}
+	return "unknown"
+}
+
+// Watcher receives the connection events on its own goroutine once added to
+// the ZookeeperClient, so a slow watcher does not stall the others.
 type Watcher struct {
-	f func(event zk.Event)
+	f         func(event zk.Event)
+	queueSize int
+	policy    WatcherOverflowPolicy
+	mutex     sync.Mutex
+	queue     chan zk.Event
+	quit      chan struct{}
 }
 
 func NewWatcher(f func(zk.Event)) *Watcher {
-	return &Watcher{f}
+	return &Watcher{
+		f:         f,
+		queueSize: DefaultWatcherQueueSize,
+		policy:    WatcherOverflowCoalesce,
+	}
+}
+
+func (w *Watcher) WithQueueSize(queueSize int) *Watcher {
+	w.queueSize = queueSize
+	return w
+}
+
+func (w *Watcher) WithOverflowPolicy(policy WatcherOverflowPolicy) *Watcher {
+	w.policy = policy
+	return w
 }
 
+// Fire calls the callback synchronously.
 func (w *Watcher) Fire(event zk.Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			Log.Errorln("curator: watcher panic, event:", event, "recover:", r)
+		}
+	}()
 	w.f(event)
 }
 
+func (w *Watcher) start() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.quit != nil {
+		return
+	}
+	queueSize := w.queueSize
+	if queueSize <= 0 {
+		queueSize = 1
+	}
+	w.queue = make(chan zk.Event, queueSize)
+	w.quit = make(chan struct{})
+	go w.dispatch(w.queue, w.quit)
+}
+
+// stop does not wait for the running callback, so a watcher may remove
+// itself from its callback.
+func (w *Watcher) stop() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.quit != nil {
+		close(w.quit)
+		w.queue, w.quit = nil, nil
+	}
+}
+
+func (w *Watcher) enqueue(event zk.Event) {
+	w.mutex.Lock()
+	queue, quit := w.queue, w.quit
+	if queue == nil {
+		w.mutex.Unlock()
+		return
+	}
+
+	select {
+	case queue <- event:
+		w.mutex.Unlock()
+		return
+	default:
+	}
+
+	switch w.policy {
+	case WatcherOverflowDrop:
+		w.mutex.Unlock()
+		Log.Warnln("curator: watcher queue is full, drop event:", event)
+	case WatcherOverflowCoalesce:
+		// producers hold the mutex, so the queue cannot be filled in between
+		select {
+		case dropped := <-queue:
+			Log.Warnln("curator: watcher queue is full, drop event:", dropped)
+		default:
+		}
+		queue <- event
+		w.mutex.Unlock()
+	default:
+		w.mutex.Unlock()
+		select {
+		case queue <- event:
+		case <-quit:
+		}
+	}
+}
+
+func (w *Watcher) dispatch(queue <-chan zk.Event, quit <-chan struct{}) {
+	for {
+		select {
+		case <-quit:
+			return
+		case event := <-queue:
+			select {
+			case <-quit:
+				return
+			default:
+			}
+			w.Fire(event)
+		}
+	}
+}
+
 type watcherManager struct {
 	mutex    sync.Mutex
-	watchers map[*Watcher]*Watcher
+	watchers []*Watcher
 }
 
 func newWatcherManager() *watcherManager {
-	return &watcherManager{
-		watchers: make(map[*Watcher]*Watcher),
-	}
+	return &watcherManager{}
 }
 
 func (w *watcherManager) AddWatcher(watcher *Watcher) {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
-	w.watchers[watcher] = watcher
+
+	for _, v := range w.watchers {
+		if v == watcher {
+			return
+		}
+	}
+	w.watchers = append(w.watchers, watcher)
+	watcher.start()
 }
 
 func (w *watcherManager) DelWatcher(watcher *Watcher) {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
-	delete(w.watchers, watcher)
+
+	for i, v := range w.watchers {
+		if v == watcher {
+			w.watchers = append(w.watchers[:i:i], w.watchers[i+1:]...)
+			watcher.stop()
+			return
+		}
+	}
 }
 
+// Fire queues the event to every watcher in the order they were added.
 func (w *watcherManager) Fire(event zk.Event) {
-	m := make(map[*Watcher]*Watcher)
-
 	w.mutex.Lock()
-	for k, v := range w.watchers {
-		m[k] = v
-	}
+	watchers := append([]*Watcher(nil), w.watchers...)
 	w.mutex.Unlock()
 
-	for _, v := range m {
-		v.Fire(event)
+	for _, v := range watchers {
+		v.enqueue(event)
 	}
 }
@@ -0,0 +1,112 @@
+package curator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestWatcherManager_Fire(t *testing.T) {
+	manager := newWatcherManager()
+
+	c := make(chan zk.State, 10)
+	panicWatcher := NewWatcher(func(event zk.Event) {
+		panic("boom")
+	})
+	watcher := NewWatcher(func(event zk.Event) {
+		c <- event.State
+	})
+	manager.AddWatcher(panicWatcher)
+	manager.AddWatcher(watcher)
+	defer manager.DelWatcher(panicWatcher)
+
+	states := []zk.State{zk.StateConnecting, zk.StateConnected, zk.StateHasSession}
+	for _, state := range states {
+		manager.Fire(zk.Event{Type: zk.EventSession, State: state})
+	}
+	for _, expected := range states {
+		select {
+		case state := <-c:
+			if state != expected {
+				t.Fatal("unexpected state, expected:", expected, "but:", state)
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatal("deadline waiting for", expected)
+		}
+	}
+
+	manager.DelWatcher(watcher)
+	manager.Fire(zk.Event{Type: zk.EventSession, State: zk.StateDisconnected})
+	select {
+	case state := <-c:
+		t.Fatal("unexpected state after DelWatcher:", state)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWatcherManager_Overflow(t *testing.T) {
+	for _, policy := range []WatcherOverflowPolicy{WatcherOverflowDrop, WatcherOverflowCoalesce} {
+		manager := newWatcherManager()
+
+		block := make(chan struct{})
+		c := make(chan zk.State, 10)
+		watcher := NewWatcher(func(event zk.Event) {
+			<-block
+			c <- event.State
+		}).WithQueueSize(1).WithOverflowPolicy(policy)
+		manager.AddWatcher(watcher)
+
+		states := []zk.State{zk.StateConnecting, zk.StateConnected, zk.StateHasSession}
+		for _, state := range states {
+			manager.Fire(zk.Event{Type: zk.EventSession, State: state})
+			// let the watcher take the first event
+			time.Sleep(10 * time.Millisecond)
+		}
+		close(block)
+
+		expected := []zk.State{zk.StateConnecting, zk.StateConnected}
+		if policy == WatcherOverflowCoalesce {
+			expected = []zk.State{zk.StateConnecting, zk.StateHasSession}
+		}
+		for _, v := range expected {
+			select {
+			case state := <-c:
+				if state != v {
+					t.Fatal(policy, "unexpected state, expected:", v, "but:", state)
+				}
+			case <-time.After(1 * time.Second):
+				t.Fatal(policy, "deadline waiting for", v)
+			}
+		}
+		select {
+		case state := <-c:
+			t.Fatal(policy, "unexpected state:", state)
+		case <-time.After(50 * time.Millisecond):
+		}
+		manager.DelWatcher(watcher)
+	}
+}
+
+func TestWatcherManager_DefaultDoesNotBlock(t *testing.T) {
+	manager := newWatcherManager()
+
+	block := make(chan struct{})
+	defer close(block)
+	manager.AddWatcher(NewWatcher(func(event zk.Event) {
+		<-block
+	}).WithQueueSize(1))
+
+	fired := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			manager.Fire(zk.Event{Type: zk.EventSession, State: zk.StateConnecting})
+		}
+		close(fired)
+	}()
+	select {
+	case <-fired:
+	case <-time.After(1 * time.Second):
+		t.Fatal("Fire blocked on a slow watcher")
+	}
+}
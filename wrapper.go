@@ -1,9 +1,20 @@
 package curator
 
-import "github.com/samuel/go-zookeeper/zk"
+import (
+	"context"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
 
 func (c *ZookeeperClient) Get(path string) (data []byte, stat *zk.Stat, err error) {
-	CallWithRetryLoop(c, func() error {
+	return c.GetCtx(context.Background(), path)
+}
+
+func (c *ZookeeperClient) GetCtx(ctx context.Context, path string) (data []byte, stat *zk.Stat, err error) {
+	if path, err = c.fixPath(path); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
 		data, stat, err = c.GetConn().Get(path)
 		return err
 	})
@@ -11,15 +22,30 @@ func (c *ZookeeperClient) Get(path string) (data []byte, stat *zk.Stat, err erro
 }
 
 func (c *ZookeeperClient) GetW(path string) (data []byte, stat *zk.Stat, watch <-chan zk.Event, err error) {
-	CallWithRetryLoop(c, func() error {
+	return c.GetWCtx(context.Background(), path)
+}
+
+func (c *ZookeeperClient) GetWCtx(ctx context.Context, path string) (data []byte, stat *zk.Stat, watch <-chan zk.Event, err error) {
+	if path, err = c.fixPath(path); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
 		data, stat, watch, err = c.GetConn().GetW(path)
 		return err
 	})
+	watch = c.unfixWatch(watch)
 	return
 }
 
 func (c *ZookeeperClient) Children(path string) (children []string, stat *zk.Stat, err error) {
-	CallWithRetryLoop(c, func() error {
+	return c.ChildrenCtx(context.Background(), path)
+}
+
+func (c *ZookeeperClient) ChildrenCtx(ctx context.Context, path string) (children []string, stat *zk.Stat, err error) {
+	if path, err = c.fixPath(path); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
 		children, stat, err = c.GetConn().Children(path)
 		return err
 	})
@@ -27,15 +53,30 @@ func (c *ZookeeperClient) Children(path string) (children []string, stat *zk.Sta
 }
 
 func (c *ZookeeperClient) ChildrenW(path string) (children []string, stat *zk.Stat, watch <-chan zk.Event, err error) {
-	CallWithRetryLoop(c, func() error {
+	return c.ChildrenWCtx(context.Background(), path)
+}
+
+func (c *ZookeeperClient) ChildrenWCtx(ctx context.Context, path string) (children []string, stat *zk.Stat, watch <-chan zk.Event, err error) {
+	if path, err = c.fixPath(path); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
 		children, stat, watch, err = c.GetConn().ChildrenW(path)
 		return err
 	})
+	watch = c.unfixWatch(watch)
 	return
 }
 
 func (c *ZookeeperClient) Exists(path string) (exist bool, stat *zk.Stat, err error) {
-	CallWithRetryLoop(c, func() error {
+	return c.ExistsCtx(context.Background(), path)
+}
+
+func (c *ZookeeperClient) ExistsCtx(ctx context.Context, path string) (exist bool, stat *zk.Stat, err error) {
+	if path, err = c.fixPath(path); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
 		exist, stat, err = c.GetConn().Exists(path)
 		return err
 	})
@@ -43,47 +84,102 @@ func (c *ZookeeperClient) Exists(path string) (exist bool, stat *zk.Stat, err er
 }
 
 func (c *ZookeeperClient) ExistsW(path string) (exist bool, stat *zk.Stat, watch <-chan zk.Event, err error) {
-	CallWithRetryLoop(c, func() error {
+	return c.ExistsWCtx(context.Background(), path)
+}
+
+func (c *ZookeeperClient) ExistsWCtx(ctx context.Context, path string) (exist bool, stat *zk.Stat, watch <-chan zk.Event, err error) {
+	if path, err = c.fixPath(path); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
 		exist, stat, watch, err = c.GetConn().ExistsW(path)
 		return err
 	})
+	watch = c.unfixWatch(watch)
 	return
 }
 
 func (c *ZookeeperClient) Create(path string, value []byte, flags int32, aclv []zk.ACL) (pathCreated string, err error) {
-	CallWithRetryLoop(c, func() error {
-		pathCreated, err = c.GetConn().Create(path, value, flags, aclv)
+	return c.CreateCtx(context.Background(), path, value, flags, aclv)
+}
+
+func (c *ZookeeperClient) CreateCtx(ctx context.Context, path string, value []byte, flags int32, aclv []zk.ACL) (pathCreated string, err error) {
+	if path, err = c.fixPath(path); err != nil {
+		return
+	}
+	if err = c.ensureNamespace(ctx); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
+		pathCreated, err = c.getWritableConn().Create(path, value, flags, aclv)
 		return err
 	})
+	if err == nil {
+		pathCreated = c.unfixPath(pathCreated)
+	}
 	return
 }
 
 func (c *ZookeeperClient) CreateProtectedEphemeralSequential(path string, value []byte, aclv []zk.ACL) (pathCreated string, err error) {
-	CallWithRetryLoop(c, func() error {
-		pathCreated, err = c.GetConn().CreateProtectedEphemeralSequential(path, value, aclv)
+	return c.CreateProtectedEphemeralSequentialCtx(context.Background(), path, value, aclv)
+}
+
+func (c *ZookeeperClient) CreateProtectedEphemeralSequentialCtx(ctx context.Context, path string, value []byte, aclv []zk.ACL) (pathCreated string, err error) {
+	if path, err = c.fixPath(path); err != nil {
+		return
+	}
+	if err = c.ensureNamespace(ctx); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
+		pathCreated, err = c.getWritableConn().CreateProtectedEphemeralSequential(path, value, aclv)
 		return err
 	})
+	if err == nil {
+		pathCreated = c.unfixPath(pathCreated)
+	}
 	return
 }
 
 func (c *ZookeeperClient) Set(path string, value []byte, version int32) (stat *zk.Stat, err error) {
-	CallWithRetryLoop(c, func() error {
-		stat, err = c.GetConn().Set(path, value, version)
+	return c.SetCtx(context.Background(), path, value, version)
+}
+
+func (c *ZookeeperClient) SetCtx(ctx context.Context, path string, value []byte, version int32) (stat *zk.Stat, err error) {
+	if path, err = c.fixPath(path); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
+		stat, err = c.getWritableConn().Set(path, value, version)
 		return err
 	})
 	return
 }
 
 func (c *ZookeeperClient) Delete(path string, version int32) (err error) {
-	CallWithRetryLoop(c, func() error {
-		err = c.GetConn().Delete(path, version)
+	return c.DeleteCtx(context.Background(), path, version)
+}
+
+func (c *ZookeeperClient) DeleteCtx(ctx context.Context, path string, version int32) (err error) {
+	if path, err = c.fixPath(path); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
+		err = c.getWritableConn().Delete(path, version)
 		return err
 	})
 	return
 }
 
 func (c *ZookeeperClient) GetACL(path string) (acl []zk.ACL, stat *zk.Stat, err error) {
-	CallWithRetryLoop(c, func() error {
+	return c.GetACLCtx(context.Background(), path)
+}
+
+func (c *ZookeeperClient) GetACLCtx(ctx context.Context, path string) (acl []zk.ACL, stat *zk.Stat, err error) {
+	if path, err = c.fixPath(path); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
 		acl, stat, err = c.GetConn().GetACL(path)
 		return err
 	})
@@ -91,9 +187,39 @@ func (c *ZookeeperClient) GetACL(path string) (acl []zk.ACL, stat *zk.Stat, err
 }
 
 func (c *ZookeeperClient) SetACL(path string, acl []zk.ACL, version int32) (stat *zk.Stat, err error) {
-	CallWithRetryLoop(c, func() error {
-		stat, err = c.GetConn().SetACL(path, acl, version)
+	return c.SetACLCtx(context.Background(), path, acl, version)
+}
+
+func (c *ZookeeperClient) SetACLCtx(ctx context.Context, path string, acl []zk.ACL, version int32) (stat *zk.Stat, err error) {
+	if path, err = c.fixPath(path); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
+		stat, err = c.getWritableConn().SetACL(path, acl, version)
+		return err
+	})
+	return
+}
+
+func (c *ZookeeperClient) Multi(ops ...interface{}) (responses []zk.MultiResponse, err error) {
+	return c.MultiCtx(context.Background(), ops...)
+}
+
+func (c *ZookeeperClient) MultiCtx(ctx context.Context, ops ...interface{}) (responses []zk.MultiResponse, err error) {
+	if ops, err = c.fixOps(ops); err != nil {
+		return
+	}
+	if err = c.ensureNamespace(ctx); err != nil {
+		return
+	}
+	err = CallWithRetryLoopCtx(ctx, c, func() error {
+		responses, err = c.getWritableConn().Multi(ops...)
 		return err
 	})
+	for i := range responses {
+		if responses[i].String != "" {
+			responses[i].String = c.unfixPath(responses[i].String)
+		}
+	}
 	return
 }
@@ -1,8 +1,11 @@
 package curator
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
 )
@@ -52,3 +55,44 @@ func TestZookeeperClient_SetACL(t *testing.T) {
 		t.Fatal("unexpected acl")
 	}
 }
+
+func TestZookeeperClient_GetCtx(t *testing.T) {
+	client, err := NewZookeeperClient(DefaultZookeeperFactory, NewFixedEnsembleProvider("127.0.0.1:2181"),
+		3*time.Second, 1*time.Second, NewRetryForever(100*time.Millisecond), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err = client.GetCtx(ctx, "/zookeeper")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("unexpected err:", err)
+	}
+	if _, ok := err.(*ContextError); !ok {
+		t.Fatal("unexpected err type:", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatal("GetCtx did not honor the deadline, elapsed:", elapsed)
+	}
+
+	// the last ZooKeeper error is wrapped too once an attempt was made
+	ctx, cancel = context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	_, _, err = client.GetCtx(ctx, "/zookeeper")
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, zk.ErrNoServer) {
+		t.Fatal("unexpected err:", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.CreateCtx(ctx, "/zookeeper/ctx", nil, 0, zk.WorldACL(zk.PermAll)); !errors.Is(err, context.Canceled) {
+		t.Fatal("unexpected err:", err)
+	}
+}
@@ -1,6 +1,7 @@
 package curator
 
 import (
+	"context"
 	"errors"
 	"sync/atomic"
 	"time"
@@ -23,6 +24,8 @@ type ZookeeperClient struct {
 	retryPolicy       RetryPolicy
 	quit              chan struct{}
 	connectionTimeout time.Duration
+	parent            *ZookeeperClient
+	namespace         *namespace
 }
 
 func NewZookeeperClient(
@@ -56,21 +59,33 @@ func NewZookeeperClient(
 }
 
 func (c *ZookeeperClient) Start() error {
+	if c.parent != nil {
+		return errors.New("curator: cannot start the namespace view of ZookeeperClient")
+	}
 	if !atomic.CompareAndSwapInt32(&c.started, 0, 1) {
 		return errors.New("curator: ZookeeperClient already started")
 	}
+	c.stateManager.start(c)
 	return c.connectionState.start()
 }
 
 func (c *ZookeeperClient) Close() error {
+	if c.parent != nil {
+		return errors.New("curator: cannot close the namespace view of ZookeeperClient")
+	}
 	if atomic.CompareAndSwapInt32(&c.started, 1, 0) {
 		c.connectionState.Close()
+		c.stateManager.close()
 	}
 	return nil
 }
 
+func (c *ZookeeperClient) GetConnectionStateListenable() ConnectionStateListenable {
+	return c.stateManager
+}
+
 func (c *ZookeeperClient) GetConn() Conn {
-	if atomic.LoadInt32(&c.started) != 1 {
+	if !c.isStarted() {
 		return dummyConn{ErrClientClosed}
 	}
 
@@ -81,16 +96,42 @@ func (c *ZookeeperClient) GetConn() Conn {
 	return conn
 }
 
+// getWritableConn fails the write operations fast while the session is
+// read-only, instead of letting the server reject them.
+func (c *ZookeeperClient) getWritableConn() Conn {
+	if c.IsReadOnly() {
+		return dummyConn{ErrReadOnly}
+	}
+	return c.GetConn()
+}
+
+func (c *ZookeeperClient) isStarted() bool {
+	if c.parent != nil {
+		return c.parent.isStarted()
+	}
+	return atomic.LoadInt32(&c.started) == 1
+}
+
 func (c *ZookeeperClient) GetRetryPolicy() RetryPolicy {
 	return c.retryPolicy
 }
 
 func (c *ZookeeperClient) BlockUntilConnectedOrTimeout() {
-	if atomic.LoadInt32(&c.started) != 1 {
-		return
+	c.BlockUntilConnectedOrTimeoutCtx(context.Background())
+}
+
+func (c *ZookeeperClient) BlockUntilConnectedOrTimeoutCtx(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !c.isStarted() {
+		return nil
 	}
 
 	timer := time.NewTimer(1 * time.Second)
+	defer timer.Stop()
+
 	waitTime := c.connectionTimeout
 	for !c.connectionState.IsConnected() && waitTime > 0 {
 		w := make(chan zk.State, 1)
@@ -105,18 +146,25 @@ func (c *ZookeeperClient) BlockUntilConnectedOrTimeout() {
 
 		start := time.Now()
 		quit := false
+		var err error
 		select {
 		case <-c.quit:
 			quit = true
+		case <-ctx.Done():
+			err = ctx.Err()
 		case <-w:
 		case <-timer.C:
 		}
 		c.DelWatcher(watcher)
 
+		if err != nil {
+			return err
+		}
 		if quit {
 			break
 		}
 
 		waitTime -= time.Since(start)
 	}
+	return nil
 }
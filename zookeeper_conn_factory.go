@@ -1,8 +1,12 @@
 package curator
 
 import (
+	"bytes"
+	"encoding/binary"
+	"io"
 	"net"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
@@ -16,8 +20,40 @@ type defaultZookeeperFactory struct{}
 
 var DefaultZookeeperFactory = defaultZookeeperFactory{}
 
+// Create requests a read-only session when readOnly is set. zk has no such
+// option, so the flag is appended to the connect request on the wire, and
+// StateHasSession is reported as StateConnectedReadOnly when the server grants
+// a read-only session.
 func (defaultZookeeperFactory) Create(connectString string, sessionTimeout, connectTimeout time.Duration, readOnly bool) (*zk.Conn, <-chan zk.Event, error) {
-	return zk.Connect(strings.Split(connectString, ","), sessionTimeout, zk.WithDialer(dialWithTimeout(connectTimeout)))
+	dialer := dialWithTimeout(connectTimeout)
+	if !readOnly {
+		return zk.Connect(strings.Split(connectString, ","), sessionTimeout, zk.WithDialer(dialer))
+	}
+
+	var granted int32
+	conn, eventChan, err := zk.Connect(strings.Split(connectString, ","), sessionTimeout, zk.WithDialer(
+		func(network, address string, timeout time.Duration) (net.Conn, error) {
+			conn, err := dialer(network, address, timeout)
+			if err != nil {
+				return nil, err
+			}
+			return &readOnlyConn{Conn: conn, granted: &granted}, nil
+		}))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events := make(chan zk.Event, 6)
+	go func() {
+		defer close(events)
+		for event := range eventChan {
+			if event.Type == zk.EventSession && event.State == zk.StateHasSession && atomic.LoadInt32(&granted) == 1 {
+				event.State = zk.StateConnectedReadOnly
+			}
+			events <- event
+		}
+	}()
+	return conn, events, nil
 }
 
 func dialWithTimeout(timeout time.Duration) zk.Dialer {
@@ -25,3 +61,73 @@ func dialWithTimeout(timeout time.Duration) zk.Dialer {
 		return net.DialTimeout(network, address, timeout)
 	}
 }
+
+// readOnlyConn appends the readOnly flag to the connect request, the first
+// packet written, and reads the flag from the connect response, the first
+// packet read. Later packets pass through untouched. zk reads and writes on
+// their own goroutines, so only requested is shared between them.
+type readOnlyConn struct {
+	net.Conn
+	granted   *int32
+	requested int32
+	responded bool
+	pending   bytes.Reader
+}
+
+func (c *readOnlyConn) Write(b []byte) (int, error) {
+	if len(b) < 4 || !atomic.CompareAndSwapInt32(&c.requested, 0, 1) {
+		return c.Conn.Write(b)
+	}
+
+	packet := make([]byte, len(b)+1)
+	copy(packet, b)
+	packet[len(b)] = 1
+	binary.BigEndian.PutUint32(packet[:4], binary.BigEndian.Uint32(b[:4])+1)
+	if _, err := c.Conn.Write(packet); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *readOnlyConn) Read(b []byte) (int, error) {
+	if !c.responded && atomic.LoadInt32(&c.requested) == 1 {
+		c.responded = true
+		packet, err := c.readConnectResponse()
+		if err != nil {
+			return 0, err
+		}
+		c.pending.Reset(packet)
+	}
+	if c.pending.Len() > 0 {
+		return c.pending.Read(b)
+	}
+	return c.Conn.Read(b)
+}
+
+// readConnectResponse reads the whole response: protocolVersion, timeOut,
+// sessionID, passwd and, from servers knowing it, the readOnly flag.
+func (c *readOnlyConn) readConnectResponse() ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(c.Conn, header); err != nil {
+		return nil, err
+	}
+	packet := make([]byte, 4+int(binary.BigEndian.Uint32(header)))
+	copy(packet, header)
+	if _, err := io.ReadFull(c.Conn, packet[4:]); err != nil {
+		return nil, err
+	}
+
+	body := packet[4:]
+	granted := int32(0)
+	if len(body) >= 20 {
+		passwdLen := int(int32(binary.BigEndian.Uint32(body[16:20])))
+		if passwdLen < 0 {
+			passwdLen = 0
+		}
+		if len(body) == 20+passwdLen+1 && body[len(body)-1] == 1 {
+			granted = 1
+		}
+	}
+	atomic.StoreInt32(c.granted, granted)
+	return packet, nil
+}
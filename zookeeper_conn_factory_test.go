@@ -0,0 +1,122 @@
+package curator
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+func TestReadOnlyConn_Handshake(t *testing.T) {
+	for _, readOnly := range []bool{true, false} {
+		client, server := net.Pipe()
+		var granted int32 = -1
+		conn := &readOnlyConn{Conn: client, granted: &granted}
+
+		errChan := make(chan string, 1)
+		go func() {
+			defer server.Close()
+			header := make([]byte, 4)
+			io.ReadFull(server, header)
+			request := make([]byte, binary.BigEndian.Uint32(header))
+			io.ReadFull(server, request)
+			if len(request) != 5 || request[4] != 1 {
+				errChan <- "unexpected connect request"
+				return
+			}
+
+			// protocolVersion, timeOut, sessionID, passwd of 2 bytes and readOnly
+			response := make([]byte, 4+20+2+1)
+			binary.BigEndian.PutUint32(response[0:4], uint32(len(response)-4))
+			binary.BigEndian.PutUint32(response[20:24], 2)
+			if readOnly {
+				response[len(response)-1] = 1
+			}
+			server.Write(append(response, 0, 0, 0, 1, 'x'))
+			errChan <- ""
+		}()
+
+		if n, err := conn.Write([]byte{0, 0, 0, 4, 1, 2, 3, 4}); err != nil || n != 8 {
+			t.Fatal("failed to write the connect request, n:", n, "err:", err)
+		}
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			t.Fatal("failed to read the connect response, err:", err)
+		}
+		response := make([]byte, binary.BigEndian.Uint32(header))
+		if _, err := io.ReadFull(conn, response); err != nil || len(response) != 23 {
+			t.Fatal("failed to read the connect response, err:", err)
+		}
+		if (granted == 1) != readOnly {
+			t.Fatal("unexpected granted:", granted, "readOnly:", readOnly)
+		}
+
+		packet := make([]byte, 5)
+		if _, err := io.ReadFull(conn, packet); err != nil || packet[4] != 'x' {
+			t.Fatal("unexpected packet after the handshake:", packet, "err:", err)
+		}
+		if msg := <-errChan; msg != "" {
+			t.Fatal(msg)
+		}
+		conn.Close()
+	}
+}
+
+func TestZookeeperClient_ReadOnly(t *testing.T) {
+	client, err := newZooKeeperClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	nodePath := "/test/readonly"
+	DeleteAll(client, nodePath)
+	if _, err := CreateAll(client, nodePath, []byte("data"), 0, zk.WorldACL(zk.PermAll)); err != nil {
+		t.Fatal("failed to create node, err:", err)
+	}
+	defer DeleteAll(client, nodePath)
+
+	states := make(chan ConnectionState, 10)
+	listener := &mockConnectionStateListener{c: states}
+	client.GetConnectionStateListenable().AddListener(listener)
+	defer client.GetConnectionStateListenable().RemoveListener(listener)
+
+	// the event the default factory reports for a read-only session
+	client.processEvent(zk.Event{Type: zk.EventSession, State: zk.StateConnectedReadOnly})
+	if !client.IsReadOnly() {
+		t.Fatal("client must be read-only")
+	}
+	select {
+	case state := <-states:
+		if state != ConnectionStateReadOnly {
+			t.Fatal("unexpected state:", state)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no ReadOnly state delivered")
+	}
+
+	if data, _, err := client.Get(nodePath); err != nil || string(data) != "data" {
+		t.Fatal("failed to read in read-only mode, data:", string(data), "err:", err)
+	}
+	if _, err := client.Set(nodePath, []byte("new"), -1); err != ErrReadOnly {
+		t.Fatal("unexpected Set result:", err)
+	}
+	if _, err := client.Create(path.Join(nodePath, "child"), nil, 0, zk.WorldACL(zk.PermAll)); err != ErrReadOnly {
+		t.Fatal("unexpected Create result:", err)
+	}
+	if err := client.Delete(nodePath, -1); err != ErrReadOnly {
+		t.Fatal("unexpected Delete result:", err)
+	}
+
+	client.processEvent(zk.Event{Type: zk.EventSession, State: zk.StateHasSession})
+	if client.IsReadOnly() {
+		t.Fatal("client must not be read-only")
+	}
+	if _, err := client.Set(nodePath, []byte("new"), -1); err != nil {
+		t.Fatal("failed to Set, err:", err)
+	}
+}